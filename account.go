@@ -1,17 +1,11 @@
 package main
 
-import (
-	"math/rand"
-	"time"
-
-	"golang.org/x/crypto/bcrypt"
-)
-
-var bcryptCost = bcrypt.DefaultCost
+import "github.com/AbhiAnand-1011/siuu-bank/store"
 
 type LoginResponse struct {
-	Number int64  `json:"number"`
-	Token  string `json:"token"`
+	Number       int64  `json:"number"`
+	Token        string `json:"token"`
+	RefreshToken string `json:"refreshToken"`
 }
 
 type LoginRequest struct {
@@ -19,6 +13,10 @@ type LoginRequest struct {
 	Password string `json:"password"`
 }
 
+type RefreshRequest struct {
+	RefreshToken string `json:"refreshToken"`
+}
+
 type TransferRequest struct {
 	ToAccount int `json:"toAccount"`
 	Amount    int `json:"amount"`
@@ -30,65 +28,12 @@ type CreateAccountRequest struct {
 	Password  string `json:"password"`
 }
 
-type Account struct {
-	ID                int       `json:"id"`
-	FirstName         string    `json:"firstName"`
-	LastName          string    `json:"lastName"`
-	Number            int64     `json:"number"`
-	EncryptedPassword string    `json:"-"`
-	Balance           int64     `json:"balance"`
-	CreatedAt         time.Time `json:"createdAt"`
-}
-
-type AccountView struct {
-	ID        int       `json:"id"`
-	FirstName string    `json:"firstName"`
-	LastName  string    `json:"lastName"`
-	Number    int64     `json:"number"`
-	Balance   int64     `json:"balance"`
-	CreatedAt time.Time `json:"createdAt"`
-}
-
-func (a *Account) View() AccountView {
-	return AccountView{
-		ID:        a.ID,
-		FirstName: a.FirstName,
-		LastName:  a.LastName,
-		Number:    a.Number,
-		Balance:   a.Balance,
-		CreatedAt: a.CreatedAt,
-	}
+type CreateSubaccountRequest struct {
+	FirstName string            `json:"firstName"`
+	LastName  string            `json:"lastName"`
+	Type      store.AccountType `json:"type"`
 }
 
-func (a *Account) FullName() string {
-	return a.FirstName + " " + a.LastName
-}
-
-func (a *Account) ValidPassword(pw string) bool {
-	return bcrypt.CompareHashAndPassword(
-		[]byte(a.EncryptedPassword),
-		[]byte(pw),
-	) == nil
-}
-
-func generateAccountNumber() int64 {
-	return rand.Int63n(1_000_000_000_000)
-}
-
-func NewAccount(firstName, lastName, password string) (*Account, error) {
-	encpw, err := bcrypt.GenerateFromPassword(
-		[]byte(password),
-		bcryptCost,
-	)
-	if err != nil {
-		return nil, err
-	}
-
-	return &Account{
-		FirstName:         firstName,
-		LastName:          lastName,
-		EncryptedPassword: string(encpw),
-		Number:            generateAccountNumber(),
-		CreatedAt:         time.Now().UTC(),
-	}, nil
+type SetRoleRequest struct {
+	Role store.Role `json:"role"`
 }
@@ -9,33 +9,51 @@ import (
 	"os"
 	"strconv"
 	"strings"
+	"time"
 
+	"github.com/AbhiAnand-1011/siuu-bank/store"
 	jwt "github.com/golang-jwt/jwt/v4"
 	"github.com/gorilla/mux"
 )
 
 type APIServer struct {
 	listenAddr string
-	store      Storage
+	store      store.Storage
 }
 
-func NewAPIServer(listenAddr string, store Storage) *APIServer {
+func NewAPIServer(listenAddr string, store store.Storage) *APIServer {
 	return &APIServer{
 		listenAddr: listenAddr,
 		store:      store,
 	}
 }
 
-func (s *APIServer) Run() {
+// router builds the mux used by Run, split out so tests can exercise the
+// full handler chain (including middleware) with httptest.NewServer
+// instead of hitting a real listener.
+func (s *APIServer) router() *mux.Router {
 	router := mux.NewRouter()
 
 	router.HandleFunc("/login", makeHTTPHandleFunc(s.handleLogin))
-	router.HandleFunc("/account", makeHTTPHandleFunc(s.handleAccount))
-	router.HandleFunc("/account/{id}", withJWTAuth(makeHTTPHandleFunc(s.handleGetAccountByID), s.store))
+	router.HandleFunc("/auth/refresh", makeHTTPHandleFunc(s.handleRefresh))
+	router.HandleFunc("/auth/logout", withJWTAuth(makeHTTPHandleFunc(s.handleLogout), s.store))
+	router.HandleFunc("/auth/logout-all", withJWTAuth(makeHTTPHandleFunc(s.handleLogoutAll), s.store))
+	router.HandleFunc("/account", makeHTTPHandleFunc(s.handleCreateAccount)).Methods(http.MethodPost)
+	router.HandleFunc("/account", withJWTAuth(requireRole(string(store.RoleAdmin), makeHTTPHandleFunc(s.handleGetAccount)), s.store)).Methods(http.MethodGet)
+	router.HandleFunc("/account/{id:[0-9]+}", withJWTAuth(makeHTTPHandleFunc(s.handleGetAccountByID), s.store)).Methods(http.MethodGet)
+	router.HandleFunc("/account/{id:[0-9]+}", withJWTAuth(requireRole(string(store.RoleAdmin), makeHTTPHandleFunc(s.handleDeleteAccount)), s.store)).Methods(http.MethodDelete)
+	router.HandleFunc("/account/{id:[0-9]+}/subaccount", withJWTAuth(makeHTTPHandleFunc(s.handleCreateSubaccount), s.store))
+	router.HandleFunc("/account/tree", withJWTAuth(makeHTTPHandleFunc(s.handleGetAccountTree), s.store))
+	router.HandleFunc("/account/{id:[0-9]+}/ledger", withJWTAuth(makeHTTPHandleFunc(s.handleLedger), s.store))
 	router.HandleFunc("/transfer", withJWTAuth(makeHTTPHandleFunc(s.handleTransfer), s.store))
+	router.HandleFunc("/admin/accounts/{id}/role", withJWTAuth(requireRole(string(store.RoleAdmin), makeHTTPHandleFunc(s.handleSetAccountRole)), s.store)).Methods(http.MethodPost)
+
+	return router
+}
 
+func (s *APIServer) Run() {
 	log.Println("JSON API server running on port:", s.listenAddr)
-	log.Fatal(http.ListenAndServe(s.listenAddr, router))
+	log.Fatal(http.ListenAndServe(s.listenAddr, s.router()))
 }
 
 func (s *APIServer) handleLogin(w http.ResponseWriter, r *http.Request) error {
@@ -58,26 +76,134 @@ func (s *APIServer) handleLogin(w http.ResponseWriter, r *http.Request) error {
 		return newHTTPError(http.StatusUnauthorized, "invalid credentials")
 	}
 
-	token, err := createJWT(acc)
+	resp, err := s.issueSession(acc, r)
 	if err != nil {
 		return err
 	}
 
-	return WriteJSON(w, http.StatusOK, LoginResponse{
-		Token:  token,
-		Number: acc.Number,
-	})
+	return WriteJSON(w, http.StatusOK, resp)
+}
+
+// issueSession creates a new Session row for acc and returns the access
+// and refresh tokens for it.
+func (s *APIServer) issueSession(acc *store.Account, r *http.Request) (LoginResponse, error) {
+	sid, err := store.NewSessionID()
+	if err != nil {
+		return LoginResponse{}, err
+	}
+
+	refreshToken, err := store.NewRefreshToken(sid)
+	if err != nil {
+		return LoginResponse{}, err
+	}
+	refreshHash, err := store.HashRefreshToken(refreshToken)
+	if err != nil {
+		return LoginResponse{}, err
+	}
+
+	session := &store.Session{
+		ID:               sid,
+		AccountID:        acc.ID,
+		RefreshTokenHash: refreshHash,
+		ExpiresAt:        time.Now().UTC().Add(store.RefreshTokenTTL),
+		UserAgent:        r.UserAgent(),
+		IP:               r.RemoteAddr,
+	}
+	if err := s.store.CreateSession(session); err != nil {
+		return LoginResponse{}, err
+	}
+
+	accessToken, err := createJWT(acc, sid)
+	if err != nil {
+		return LoginResponse{}, err
+	}
+
+	return LoginResponse{
+		Number:       acc.Number,
+		Token:        accessToken,
+		RefreshToken: refreshToken,
+	}, nil
+}
+
+func (s *APIServer) handleRefresh(w http.ResponseWriter, r *http.Request) error {
+	if r.Method != http.MethodPost {
+		return newHTTPError(http.StatusMethodNotAllowed, "method not allowed")
+	}
+	defer r.Body.Close()
+
+	var req RefreshRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		return err
+	}
+
+	sid, err := store.SessionIDFromRefreshToken(req.RefreshToken)
+	if err != nil {
+		return newHTTPError(http.StatusUnauthorized, "invalid refresh token")
+	}
+
+	session, err := s.store.GetSessionByID(sid)
+	if err != nil {
+		return newHTTPError(http.StatusUnauthorized, "invalid refresh token")
+	}
+
+	if !session.Valid() || !session.ValidRefreshToken(req.RefreshToken) {
+		return newHTTPError(http.StatusUnauthorized, "invalid refresh token")
+	}
+
+	acc, err := s.store.GetAccountByID(session.AccountID)
+	if err != nil {
+		return err
+	}
+
+	if err := s.store.RevokeSession(session.ID); err != nil {
+		return err
+	}
+
+	resp, err := s.issueSession(acc, r)
+	if err != nil {
+		return err
+	}
+
+	return WriteJSON(w, http.StatusOK, resp)
 }
 
-func (s *APIServer) handleAccount(w http.ResponseWriter, r *http.Request) error {
-	switch r.Method {
-	case http.MethodGet:
-		return s.handleGetAccount(w, r)
-	case http.MethodPost:
-		return s.handleCreateAccount(w, r)
-	default:
+func (s *APIServer) handleLogout(w http.ResponseWriter, r *http.Request) error {
+	if r.Method != http.MethodPost {
 		return newHTTPError(http.StatusMethodNotAllowed, "method not allowed")
 	}
+
+	claims, err := claimsFromRequest(s.store, r)
+	if err != nil {
+		return err
+	}
+
+	if err := s.store.RevokeSession(claims["sid"].(string)); err != nil {
+		return err
+	}
+
+	return WriteJSON(w, http.StatusOK, map[string]string{"status": "logged out"})
+}
+
+func (s *APIServer) handleLogoutAll(w http.ResponseWriter, r *http.Request) error {
+	if r.Method != http.MethodPost {
+		return newHTTPError(http.StatusMethodNotAllowed, "method not allowed")
+	}
+
+	claims, err := claimsFromRequest(s.store, r)
+	if err != nil {
+		return err
+	}
+
+	acc, err := s.store.GetAccountByNumber(int(claims["accountNumber"].(float64)))
+	if err != nil {
+		return err
+	}
+
+	if err := s.store.RevokeAllSessions(acc.ID); err != nil {
+		return err
+	}
+
+	return WriteJSON(w, http.StatusOK, map[string]string{"status": "logged out everywhere"})
 }
 
 func (s *APIServer) handleGetAccount(w http.ResponseWriter, _ *http.Request) error {
@@ -86,7 +212,7 @@ func (s *APIServer) handleGetAccount(w http.ResponseWriter, _ *http.Request) err
 		return err
 	}
 
-	views := make([]AccountView, 0, len(accounts))
+	views := make([]store.AccountView, 0, len(accounts))
 	for _, acc := range accounts {
 		views = append(views, acc.View())
 	}
@@ -94,29 +220,43 @@ func (s *APIServer) handleGetAccount(w http.ResponseWriter, _ *http.Request) err
 	return WriteJSON(w, http.StatusOK, views)
 }
 
+// handleGetAccountByID allows an admin to look up any account, but a
+// non-admin caller may only fetch their own: we compare the claimed
+// accountNumber against the row we just loaded rather than trusting the
+// caller's id.
 func (s *APIServer) handleGetAccountByID(w http.ResponseWriter, r *http.Request) error {
 	id, err := getID(r)
 	if err != nil {
 		return err
 	}
 
-	switch r.Method {
-	case http.MethodGet:
-		account, err := s.store.GetAccountByID(id)
-		if err != nil {
-			return err
-		}
-		return WriteJSON(w, http.StatusOK, account.View())
+	account, err := s.store.GetAccountByID(id)
+	if err != nil {
+		return err
+	}
 
-	case http.MethodDelete:
-		if err := s.store.DeleteAccount(id); err != nil {
-			return err
-		}
-		return WriteJSON(w, http.StatusOK, map[string]int{"deleted": id})
+	claims, err := claimsFromRequest(s.store, r)
+	if err != nil {
+		return err
+	}
 
-	default:
-		return newHTTPError(http.StatusMethodNotAllowed, "method not allowed")
+	if err := requireSelfOrAdmin(claims, account.Number); err != nil {
+		return err
 	}
+
+	return WriteJSON(w, http.StatusOK, account.View())
+}
+
+func (s *APIServer) handleDeleteAccount(w http.ResponseWriter, r *http.Request) error {
+	id, err := getID(r)
+	if err != nil {
+		return err
+	}
+
+	if err := s.store.DeleteAccount(id); err != nil {
+		return err
+	}
+	return WriteJSON(w, http.StatusOK, map[string]int{"deleted": id})
 }
 
 func (s *APIServer) handleCreateAccount(w http.ResponseWriter, r *http.Request) error {
@@ -127,7 +267,7 @@ func (s *APIServer) handleCreateAccount(w http.ResponseWriter, r *http.Request)
 		return err
 	}
 
-	account, err := NewAccount(req.FirstName, req.LastName, req.Password)
+	account, err := store.NewAccount(req.FirstName, req.LastName, req.Password)
 	if err != nil {
 		return err
 	}
@@ -139,6 +279,75 @@ func (s *APIServer) handleCreateAccount(w http.ResponseWriter, r *http.Request)
 	return WriteJSON(w, http.StatusCreated, account.View())
 }
 
+func (s *APIServer) handleCreateSubaccount(w http.ResponseWriter, r *http.Request) error {
+	if r.Method != http.MethodPost {
+		return newHTTPError(http.StatusMethodNotAllowed, "method not allowed")
+	}
+	defer r.Body.Close()
+
+	parentID, err := getID(r)
+	if err != nil {
+		return err
+	}
+
+	parent, err := s.store.GetAccountByID(parentID)
+	if err != nil {
+		return err
+	}
+
+	claims, err := claimsFromRequest(s.store, r)
+	if err != nil {
+		return err
+	}
+
+	if err := requireSelfOrAdmin(claims, parent.Number); err != nil {
+		return err
+	}
+
+	var req CreateSubaccountRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		return err
+	}
+
+	if !req.Type.Valid() {
+		return newHTTPError(http.StatusBadRequest, fmt.Sprintf("invalid account type %q", req.Type))
+	}
+
+	account, err := store.NewChildAccount(parentID, req.Type, req.FirstName, req.LastName)
+	if err != nil {
+		return err
+	}
+
+	if err := s.store.CreateChildAccount(parentID, account); err != nil {
+		return err
+	}
+
+	return WriteJSON(w, http.StatusCreated, account.View())
+}
+
+func (s *APIServer) handleGetAccountTree(w http.ResponseWriter, r *http.Request) error {
+	if r.Method != http.MethodGet {
+		return newHTTPError(http.StatusMethodNotAllowed, "method not allowed")
+	}
+
+	claims, err := claimsFromRequest(s.store, r)
+	if err != nil {
+		return err
+	}
+
+	root, err := s.store.GetAccountByNumber(int(claims["accountNumber"].(float64)))
+	if err != nil {
+		return err
+	}
+
+	tree, err := s.store.GetAccountTree(root.ID)
+	if err != nil {
+		return err
+	}
+
+	return WriteJSON(w, http.StatusOK, tree)
+}
+
 func (s *APIServer) handleTransfer(w http.ResponseWriter, r *http.Request) error {
 	defer r.Body.Close()
 
@@ -151,18 +360,33 @@ func (s *APIServer) handleTransfer(w http.ResponseWriter, r *http.Request) error
 		return newHTTPError(http.StatusBadRequest, "invalid transfer amount")
 	}
 
-	tokenString := extractToken(r)
-	token, err := validateJWT(tokenString)
-	if err != nil || !token.Valid {
-		return newHTTPError(http.StatusUnauthorized, "invalid token")
+	claims, err := claimsFromRequest(s.store, r)
+	if err != nil {
+		return err
 	}
 
-	claims := token.Claims.(jwt.MapClaims)
-	fromNumber := int64(claims["accountNumber"].(float64))
+	fromAcc, err := s.store.GetAccountByNumber(int(claims["accountNumber"].(float64)))
+	if err != nil {
+		return err
+	}
+	toAcc, err := s.store.GetAccountByNumber(req.ToAccount)
+	if err != nil {
+		return err
+	}
 
-	err = s.store.Transfer(fromNumber, int64(req.ToAccount), int64(req.Amount))
+	if !fromAcc.Type.LedgerType() || !toAcc.Type.LedgerType() {
+		return newHTTPError(http.StatusBadRequest, fmt.Sprintf(
+			"%s: %s -> %s, post a journal entry instead", store.ErrIncompatibleAccountTypes, fromAcc.Type, toAcc.Type,
+		))
+	}
+
+	transaction, err := store.NewTransfer(fromAcc.ID, toAcc.ID, int64(req.Amount), "transfer")
 	if err != nil {
-		if errors.Is(err, ErrInsufficientFunds) {
+		return err
+	}
+
+	if err := s.store.PostTransaction(transaction); err != nil {
+		if errors.Is(err, store.ErrInsufficientFunds) {
 			return newHTTPError(http.StatusConflict, "insufficient funds")
 		}
 		return err
@@ -173,27 +397,102 @@ func (s *APIServer) handleTransfer(w http.ResponseWriter, r *http.Request) error
 	})
 }
 
+func (s *APIServer) handleSetAccountRole(w http.ResponseWriter, r *http.Request) error {
+	defer r.Body.Close()
+
+	id, err := getID(r)
+	if err != nil {
+		return err
+	}
+
+	var req SetRoleRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		return err
+	}
+
+	if !req.Role.Valid() {
+		return newHTTPError(http.StatusBadRequest, fmt.Sprintf("invalid role %q", req.Role))
+	}
+
+	if err := s.store.SetAccountRole(id, req.Role); err != nil {
+		return err
+	}
+
+	return WriteJSON(w, http.StatusOK, map[string]string{"status": "role updated"})
+}
+
+func (s *APIServer) handleLedger(w http.ResponseWriter, r *http.Request) error {
+	if r.Method != http.MethodGet {
+		return newHTTPError(http.StatusMethodNotAllowed, "method not allowed")
+	}
+
+	id, err := getID(r)
+	if err != nil {
+		return err
+	}
+
+	account, err := s.store.GetAccountByID(id)
+	if err != nil {
+		return err
+	}
+
+	claims, err := claimsFromRequest(s.store, r)
+	if err != nil {
+		return err
+	}
+
+	if err := requireSelfOrAdmin(claims, account.Number); err != nil {
+		return err
+	}
+
+	since := int64(0)
+	if raw := r.URL.Query().Get("since"); raw != "" {
+		since, err = strconv.ParseInt(raw, 10, 64)
+		if err != nil {
+			return newHTTPError(http.StatusBadRequest, "invalid since parameter")
+		}
+	}
+
+	splits, err := s.store.GetLedger(id, since)
+	if err != nil {
+		return err
+	}
+
+	return WriteJSON(w, http.StatusOK, splits)
+}
+
 func WriteJSON(w http.ResponseWriter, status int, v any) error {
 	w.Header().Set("Content-Type", "application/json")
 	w.WriteHeader(status)
 	return json.NewEncoder(w).Encode(v)
 }
 
-func createJWT(account *Account) (string, error) {
+// createJWT issues a short-lived access token for a session already
+// created via issueSession. The token carries just enough to identify
+// the session (sid) and the account (accountNumber/sub); revocation and
+// expiry of the underlying Session is what actually controls access.
+func createJWT(account *store.Account, sessionID string) (string, error) {
 	secret := os.Getenv("JWT_SECRET")
 	if secret == "" {
 		return "", fmt.Errorf("JWT_SECRET not set")
 	}
 
+	now := time.Now().UTC()
 	claims := jwt.MapClaims{
 		"accountNumber": account.Number,
+		"sub":           account.Number,
+		"sid":           sessionID,
+		"role":          string(account.Role),
+		"iat":           now.Unix(),
+		"nbf":           now.Unix(),
+		"exp":           now.Add(store.AccessTokenTTL).Unix(),
 	}
 
 	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
 	return token.SignedString([]byte(secret))
 }
 
-func withJWTAuth(handlerFunc http.HandlerFunc, s Storage) http.HandlerFunc {
+func withJWTAuth(handlerFunc http.HandlerFunc, s store.Storage) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
 		tokenString := extractToken(r)
 		if tokenString == "" {
@@ -207,10 +506,91 @@ func withJWTAuth(handlerFunc http.HandlerFunc, s Storage) http.HandlerFunc {
 			return
 		}
 
+		claims := token.Claims.(jwt.MapClaims)
+		if err := requireActiveSession(s, claims); err != nil {
+			WriteJSON(w, http.StatusUnauthorized, ApiError{Error: "session revoked"})
+			return
+		}
+
 		handlerFunc(w, r)
 	}
 }
 
+// requireRole wraps an already withJWTAuth-protected handler and rejects
+// callers whose token role claim doesn't match. It must sit inside
+// withJWTAuth so the JWT has already been parsed into request context by
+// the time it runs.
+func requireRole(role string, next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		tokenString := extractToken(r)
+		token, err := validateJWT(tokenString)
+		if err != nil || !token.Valid {
+			WriteJSON(w, http.StatusUnauthorized, ApiError{Error: "invalid token"})
+			return
+		}
+
+		claims := token.Claims.(jwt.MapClaims)
+		if claims["role"] != role {
+			WriteJSON(w, http.StatusForbidden, ApiError{Error: "forbidden"})
+			return
+		}
+
+		next(w, r)
+	}
+}
+
+// requireSelfOrAdmin rejects callers whose token isn't for accountNumber
+// itself, unless the token carries the admin role. Used wherever an {id}
+// path parameter names an account other than the one implied by the
+// caller's own accountNumber claim.
+func requireSelfOrAdmin(claims jwt.MapClaims, accountNumber int64) error {
+	if claims["role"] == string(store.RoleAdmin) {
+		return nil
+	}
+	if int64(claims["accountNumber"].(float64)) != accountNumber {
+		return newHTTPError(http.StatusForbidden, "forbidden")
+	}
+	return nil
+}
+
+// requireActiveSession looks up the Session named by claims["sid"] and
+// fails if it has been revoked or expired, closing the gap where a stolen
+// but not-yet-expired JWT would otherwise keep working after logout.
+func requireActiveSession(s store.Storage, claims jwt.MapClaims) error {
+	sid, ok := claims["sid"].(string)
+	if !ok {
+		return fmt.Errorf("token missing sid")
+	}
+
+	session, err := s.GetSessionByID(sid)
+	if err != nil {
+		return err
+	}
+	if !session.Valid() {
+		return fmt.Errorf("session %s is no longer valid", sid)
+	}
+	return nil
+}
+
+// claimsFromRequest re-validates the bearer token on r, re-checks that its
+// session is still active against s (rather than trusting the JWT's own
+// exp), and returns its claims for handlers that need more than
+// withJWTAuth's gatekeeping.
+func claimsFromRequest(s store.Storage, r *http.Request) (jwt.MapClaims, error) {
+	tokenString := extractToken(r)
+	token, err := validateJWT(tokenString)
+	if err != nil || !token.Valid {
+		return nil, newHTTPError(http.StatusUnauthorized, "invalid token")
+	}
+	claims := token.Claims.(jwt.MapClaims)
+
+	if err := requireActiveSession(s, claims); err != nil {
+		return nil, newHTTPError(http.StatusUnauthorized, "session revoked")
+	}
+
+	return claims, nil
+}
+
 func extractToken(r *http.Request) string {
 	auth := r.Header.Get("Authorization")
 	if strings.HasPrefix(auth, "Bearer ") {
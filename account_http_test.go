@@ -0,0 +1,449 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/AbhiAnand-1011/siuu-bank/store"
+	mockstore "github.com/AbhiAnand-1011/siuu-bank/store/mock"
+	"go.uber.org/mock/gomock"
+)
+
+// newTestServer wires an APIServer backed by a MockStorage to an
+// httptest.Server and returns both, so tests can assert on real HTTP
+// responses without a database.
+func newTestServer(t *testing.T) (*httptest.Server, *mockstore.MockStorage) {
+	t.Helper()
+	t.Setenv("JWT_SECRET", "test-secret")
+
+	ctrl := gomock.NewController(t)
+	ms := mockstore.NewMockStorage(ctrl)
+	server := NewAPIServer(":0", ms)
+
+	ts := httptest.NewServer(server.router())
+	t.Cleanup(ts.Close)
+
+	return ts, ms
+}
+
+// authHeaderFor mints an access token for acc backed by a session that ms
+// will report as active, and returns the header value to send it with.
+func authHeaderFor(t *testing.T, ms *mockstore.MockStorage, acc *store.Account) string {
+	t.Helper()
+
+	sid, err := store.NewSessionID()
+	if err != nil {
+		t.Fatalf("NewSessionID: %v", err)
+	}
+
+	ms.EXPECT().GetSessionByID(sid).Return(&store.Session{
+		ID:        sid,
+		AccountID: acc.ID,
+		ExpiresAt: time.Now().UTC().Add(time.Hour),
+	}, nil).AnyTimes()
+
+	token, err := createJWT(acc, sid)
+	if err != nil {
+		t.Fatalf("createJWT: %v", err)
+	}
+
+	return "Bearer " + token
+}
+
+func decodeJSON(t *testing.T, resp *http.Response, v any) {
+	t.Helper()
+	defer resp.Body.Close()
+	if err := json.NewDecoder(resp.Body).Decode(v); err != nil {
+		t.Fatalf("decode response: %v", err)
+	}
+}
+
+func TestHandleLogin(t *testing.T) {
+	ts, ms := newTestServer(t)
+
+	acc, err := store.NewAccount("abhi", "anand", "siuu")
+	if err != nil {
+		t.Fatalf("NewAccount: %v", err)
+	}
+	acc.ID = 1
+
+	ms.EXPECT().GetAccountByNumber(int(acc.Number)).Return(acc, nil)
+	ms.EXPECT().CreateSession(gomock.Any()).Return(nil)
+
+	body, _ := json.Marshal(LoginRequest{Number: acc.Number, Password: "siuu"})
+	resp, err := http.Post(ts.URL+"/login", "application/json", bytes.NewReader(body))
+	if err != nil {
+		t.Fatalf("POST /login: %v", err)
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("want 200, got %d", resp.StatusCode)
+	}
+
+	var out LoginResponse
+	decodeJSON(t, resp, &out)
+	if out.Number != acc.Number {
+		t.Errorf("want number %d, got %d", acc.Number, out.Number)
+	}
+	if out.Token == "" || out.RefreshToken == "" {
+		t.Errorf("expected non-empty tokens, got %+v", out)
+	}
+}
+
+func TestHandleLogin_WrongMethod(t *testing.T) {
+	ts, _ := newTestServer(t)
+
+	resp, err := http.Get(ts.URL + "/login")
+	if err != nil {
+		t.Fatalf("GET /login: %v", err)
+	}
+	if resp.StatusCode != http.StatusMethodNotAllowed {
+		t.Fatalf("want 405, got %d", resp.StatusCode)
+	}
+}
+
+func TestHandleLogin_MalformedBody(t *testing.T) {
+	ts, _ := newTestServer(t)
+
+	resp, err := http.Post(ts.URL+"/login", "application/json", bytes.NewReader([]byte("{not json")))
+	if err != nil {
+		t.Fatalf("POST /login: %v", err)
+	}
+	if resp.StatusCode != http.StatusBadRequest {
+		t.Fatalf("want 400, got %d", resp.StatusCode)
+	}
+}
+
+func TestHandleCreateAccount(t *testing.T) {
+	ts, ms := newTestServer(t)
+
+	ms.EXPECT().CreateAccount(gomock.Any()).Return(nil)
+
+	body, _ := json.Marshal(CreateAccountRequest{FirstName: "abhi", LastName: "anand", Password: "siuu"})
+	resp, err := http.Post(ts.URL+"/account", "application/json", bytes.NewReader(body))
+	if err != nil {
+		t.Fatalf("POST /account: %v", err)
+	}
+
+	if resp.StatusCode != http.StatusCreated {
+		t.Fatalf("want 201, got %d", resp.StatusCode)
+	}
+
+	var out store.AccountView
+	decodeJSON(t, resp, &out)
+	if out.FirstName != "abhi" {
+		t.Errorf("want firstName abhi, got %q", out.FirstName)
+	}
+}
+
+func TestHandleGetAccount_MissingToken(t *testing.T) {
+	ts, _ := newTestServer(t)
+
+	resp, err := http.Get(ts.URL + "/account")
+	if err != nil {
+		t.Fatalf("GET /account: %v", err)
+	}
+	if resp.StatusCode != http.StatusUnauthorized {
+		t.Fatalf("want 401, got %d", resp.StatusCode)
+	}
+}
+
+func TestHandleGetAccount_InvalidToken(t *testing.T) {
+	ts, _ := newTestServer(t)
+
+	req, _ := http.NewRequest(http.MethodGet, ts.URL+"/account", nil)
+	req.Header.Set("Authorization", "Bearer not-a-jwt")
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatalf("GET /account: %v", err)
+	}
+	if resp.StatusCode != http.StatusUnauthorized {
+		t.Fatalf("want 401, got %d", resp.StatusCode)
+	}
+}
+
+func TestHandleGetAccount_RequiresAdmin(t *testing.T) {
+	ts, ms := newTestServer(t)
+
+	acc, _ := store.NewAccount("abhi", "anand", "siuu")
+	acc.ID = 1
+	acc.Role = store.RoleUser
+
+	header := authHeaderFor(t, ms, acc)
+
+	req, _ := http.NewRequest(http.MethodGet, ts.URL+"/account", nil)
+	req.Header.Set("Authorization", header)
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatalf("GET /account: %v", err)
+	}
+	if resp.StatusCode != http.StatusForbidden {
+		t.Fatalf("want 403, got %d", resp.StatusCode)
+	}
+}
+
+func TestHandleGetAccount_Admin(t *testing.T) {
+	ts, ms := newTestServer(t)
+
+	admin, _ := store.NewAccount("abhi", "anand", "siuu")
+	admin.ID = 1
+	admin.Role = store.RoleAdmin
+
+	header := authHeaderFor(t, ms, admin)
+
+	ms.EXPECT().GetAccounts().Return([]*store.Account{admin}, nil)
+
+	req, _ := http.NewRequest(http.MethodGet, ts.URL+"/account", nil)
+	req.Header.Set("Authorization", header)
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatalf("GET /account: %v", err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("want 200, got %d", resp.StatusCode)
+	}
+}
+
+func TestHandleGetAccountTree(t *testing.T) {
+	ts, ms := newTestServer(t)
+
+	acc, _ := store.NewAccount("abhi", "anand", "siuu")
+	acc.ID = 1
+
+	header := authHeaderFor(t, ms, acc)
+
+	ms.EXPECT().GetAccountByNumber(int(acc.Number)).Return(acc, nil)
+	ms.EXPECT().GetAccountTree(acc.ID).Return(&store.AccountNode{AccountView: acc.View()}, nil)
+
+	req, _ := http.NewRequest(http.MethodGet, ts.URL+"/account/tree", nil)
+	req.Header.Set("Authorization", header)
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatalf("GET /account/tree: %v", err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("want 200, got %d", resp.StatusCode)
+	}
+
+	var out store.AccountNode
+	decodeJSON(t, resp, &out)
+	if out.ID != acc.ID {
+		t.Errorf("want root id %d, got %d", acc.ID, out.ID)
+	}
+}
+
+func TestHandleGetAccountByID_SelfAllowed(t *testing.T) {
+	ts, ms := newTestServer(t)
+
+	acc, _ := store.NewAccount("abhi", "anand", "siuu")
+	acc.ID = 1
+	acc.Role = store.RoleUser
+
+	header := authHeaderFor(t, ms, acc)
+	ms.EXPECT().GetAccountByID(acc.ID).Return(acc, nil)
+
+	req, _ := http.NewRequest(http.MethodGet, ts.URL+"/account/1", nil)
+	req.Header.Set("Authorization", header)
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatalf("GET /account/1: %v", err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("want 200, got %d", resp.StatusCode)
+	}
+}
+
+func TestHandleGetAccountByID_OtherForbidden(t *testing.T) {
+	ts, ms := newTestServer(t)
+
+	caller, _ := store.NewAccount("abhi", "anand", "siuu")
+	caller.ID = 1
+	caller.Role = store.RoleUser
+
+	other, _ := store.NewAccount("someone", "else", "siuu")
+	other.ID = 2
+
+	header := authHeaderFor(t, ms, caller)
+	ms.EXPECT().GetAccountByID(other.ID).Return(other, nil)
+
+	req, _ := http.NewRequest(http.MethodGet, ts.URL+"/account/2", nil)
+	req.Header.Set("Authorization", header)
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatalf("GET /account/2: %v", err)
+	}
+	if resp.StatusCode != http.StatusForbidden {
+		t.Fatalf("want 403, got %d", resp.StatusCode)
+	}
+}
+
+func TestHandleCreateSubaccount_OtherForbidden(t *testing.T) {
+	ts, ms := newTestServer(t)
+
+	caller, _ := store.NewAccount("abhi", "anand", "siuu")
+	caller.ID = 1
+	caller.Role = store.RoleUser
+
+	other, _ := store.NewAccount("someone", "else", "siuu")
+	other.ID = 2
+
+	header := authHeaderFor(t, ms, caller)
+	ms.EXPECT().GetAccountByID(other.ID).Return(other, nil)
+
+	body, _ := json.Marshal(CreateSubaccountRequest{FirstName: "sub", LastName: "account", Type: store.AccountTypeExpense})
+	req, _ := http.NewRequest(http.MethodPost, ts.URL+"/account/2/subaccount", bytes.NewReader(body))
+	req.Header.Set("Authorization", header)
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatalf("POST /account/2/subaccount: %v", err)
+	}
+	if resp.StatusCode != http.StatusForbidden {
+		t.Fatalf("want 403, got %d", resp.StatusCode)
+	}
+}
+
+func TestHandleLedger_OtherForbidden(t *testing.T) {
+	ts, ms := newTestServer(t)
+
+	caller, _ := store.NewAccount("abhi", "anand", "siuu")
+	caller.ID = 1
+	caller.Role = store.RoleUser
+
+	other, _ := store.NewAccount("someone", "else", "siuu")
+	other.ID = 2
+
+	header := authHeaderFor(t, ms, caller)
+	ms.EXPECT().GetAccountByID(other.ID).Return(other, nil)
+
+	req, _ := http.NewRequest(http.MethodGet, ts.URL+"/account/2/ledger", nil)
+	req.Header.Set("Authorization", header)
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatalf("GET /account/2/ledger: %v", err)
+	}
+	if resp.StatusCode != http.StatusForbidden {
+		t.Fatalf("want 403, got %d", resp.StatusCode)
+	}
+}
+
+func TestHandleDeleteAccount_RequiresAdmin(t *testing.T) {
+	ts, ms := newTestServer(t)
+
+	acc, _ := store.NewAccount("abhi", "anand", "siuu")
+	acc.ID = 1
+	acc.Role = store.RoleUser
+
+	header := authHeaderFor(t, ms, acc)
+
+	req, _ := http.NewRequest(http.MethodDelete, ts.URL+"/account/1", nil)
+	req.Header.Set("Authorization", header)
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatalf("DELETE /account/1: %v", err)
+	}
+	if resp.StatusCode != http.StatusForbidden {
+		t.Fatalf("want 403, got %d", resp.StatusCode)
+	}
+}
+
+func TestHandleDeleteAccount_Admin(t *testing.T) {
+	ts, ms := newTestServer(t)
+
+	admin, _ := store.NewAccount("abhi", "anand", "siuu")
+	admin.ID = 1
+	admin.Role = store.RoleAdmin
+
+	header := authHeaderFor(t, ms, admin)
+	ms.EXPECT().DeleteAccount(7).Return(nil)
+
+	req, _ := http.NewRequest(http.MethodDelete, ts.URL+"/account/7", nil)
+	req.Header.Set("Authorization", header)
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatalf("DELETE /account/7: %v", err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("want 200, got %d", resp.StatusCode)
+	}
+}
+
+func TestHandleTransfer(t *testing.T) {
+	ts, ms := newTestServer(t)
+
+	fromAcc, _ := store.NewAccount("abhi", "anand", "siuu")
+	fromAcc.ID = 1
+	fromAcc.Type = store.AccountTypeBank
+
+	toAcc, _ := store.NewAccount("other", "person", "siuu")
+	toAcc.ID = 2
+	toAcc.Type = store.AccountTypeBank
+
+	header := authHeaderFor(t, ms, fromAcc)
+
+	ms.EXPECT().GetAccountByNumber(int(fromAcc.Number)).Return(fromAcc, nil)
+	ms.EXPECT().GetAccountByNumber(int(toAcc.Number)).Return(toAcc, nil)
+	ms.EXPECT().PostTransaction(gomock.Any()).Return(nil)
+
+	body, _ := json.Marshal(TransferRequest{ToAccount: int(toAcc.Number), Amount: 100})
+	req, _ := http.NewRequest(http.MethodPost, ts.URL+"/transfer", bytes.NewReader(body))
+	req.Header.Set("Authorization", header)
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatalf("POST /transfer: %v", err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("want 200, got %d", resp.StatusCode)
+	}
+}
+
+func TestHandleTransfer_InsufficientFunds(t *testing.T) {
+	ts, ms := newTestServer(t)
+
+	fromAcc, _ := store.NewAccount("abhi", "anand", "siuu")
+	fromAcc.ID = 1
+	fromAcc.Type = store.AccountTypeBank
+
+	toAcc, _ := store.NewAccount("other", "person", "siuu")
+	toAcc.ID = 2
+	toAcc.Type = store.AccountTypeBank
+
+	header := authHeaderFor(t, ms, fromAcc)
+
+	ms.EXPECT().GetAccountByNumber(int(fromAcc.Number)).Return(fromAcc, nil)
+	ms.EXPECT().GetAccountByNumber(int(toAcc.Number)).Return(toAcc, nil)
+	ms.EXPECT().PostTransaction(gomock.Any()).Return(store.ErrInsufficientFunds)
+
+	body, _ := json.Marshal(TransferRequest{ToAccount: int(toAcc.Number), Amount: 100})
+	req, _ := http.NewRequest(http.MethodPost, ts.URL+"/transfer", bytes.NewReader(body))
+	req.Header.Set("Authorization", header)
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatalf("POST /transfer: %v", err)
+	}
+	if resp.StatusCode != http.StatusConflict {
+		t.Fatalf("want 409, got %d", resp.StatusCode)
+	}
+}
+
+func TestHandleTransfer_MalformedBody(t *testing.T) {
+	ts, ms := newTestServer(t)
+
+	fromAcc, _ := store.NewAccount("abhi", "anand", "siuu")
+	fromAcc.ID = 1
+
+	header := authHeaderFor(t, ms, fromAcc)
+
+	req, _ := http.NewRequest(http.MethodPost, ts.URL+"/transfer", bytes.NewReader([]byte("{not json")))
+	req.Header.Set("Authorization", header)
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatalf("POST /transfer: %v", err)
+	}
+	if resp.StatusCode != http.StatusBadRequest {
+		t.Fatalf("want 400, got %d", resp.StatusCode)
+	}
+}
@@ -5,15 +5,19 @@ import (
 	"fmt"
 	"log"
 	"os"
+	"strconv"
+
+	"github.com/AbhiAnand-1011/siuu-bank/migrations"
+	"github.com/AbhiAnand-1011/siuu-bank/store"
 )
 
-func seedAccount(store Storage, fname, lname, pw string) *Account {
-	acc, err := NewAccount(fname, lname, pw)
+func seedAccount(s store.Storage, fname, lname, pw string) *store.Account {
+	acc, err := store.NewAccount(fname, lname, pw)
 	if err != nil {
 		log.Fatal(err)
 	}
 
-	if err := store.CreateAccount(acc); err != nil {
+	if err := s.CreateAccount(acc); err != nil {
 		log.Fatal(err)
 	}
 
@@ -21,30 +25,50 @@ func seedAccount(store Storage, fname, lname, pw string) *Account {
 	return acc
 }
 
-func seedAccounts(store Storage) {
-	seedAccount(store, "abhi", "anand", "siuu")
+func seedAccounts(s store.Storage) *store.Account {
+	return seedAccount(s, "abhi", "anand", "siuu")
 }
 
 func main() {
 	seed := flag.Bool("seed", false, "seed the database with dummy data")
+	seedAdmin := flag.Bool("seed-admin", false, "promote the first seeded account to admin (requires -seed)")
+	migrateCmd := flag.String("migrate", "", "run a migration command (up, down, version, force) against the Postgres backend and exit")
 	flag.Parse()
 
+	if *seedAdmin && !*seed {
+		log.Fatal("-seed-admin requires -seed")
+	}
+
+	if *migrateCmd != "" {
+		if err := runMigrateCommand(*migrateCmd, flag.Args()); err != nil {
+			log.Fatal(err)
+		}
+		return
+	}
+
 	if os.Getenv("JWT_SECRET") == "" {
 		log.Fatal("JWT_SECRET environment variable must be set")
 	}
 
-	store, err := NewPostgresStore()
+	s, err := store.New()
 	if err != nil {
 		log.Fatal(err)
 	}
 
-	if err := store.Init(); err != nil {
+	if err := s.Init(); err != nil {
 		log.Fatal(err)
 	}
 
 	if *seed {
 		log.Println("seeding database")
-		seedAccounts(store)
+		acc := seedAccounts(s)
+
+		if *seedAdmin {
+			if err := s.SetAccountRole(acc.ID, store.RoleAdmin); err != nil {
+				log.Fatal(err)
+			}
+			log.Println("promoted account to admin =>", acc.Number)
+		}
 	}
 
 	addr := os.Getenv("SERVER_ADDR")
@@ -52,6 +76,57 @@ func main() {
 		addr = ":3000"
 	}
 
-	server := NewAPIServer(addr, store)
+	server := NewAPIServer(addr, s)
 	server.Run()
 }
+
+// runMigrateCommand drives migrations.Runner directly against Postgres, so
+// schema changes (e.g. "-migrate up" in a deploy step) don't require
+// standing up the whole API server first.
+func runMigrateCommand(cmd string, args []string) error {
+	pg, err := store.NewPostgresStore()
+	if err != nil {
+		return err
+	}
+
+	runner, err := migrations.New(pg.DB())
+	if err != nil {
+		return err
+	}
+
+	switch cmd {
+	case "up":
+		return runner.Up()
+
+	case "down":
+		if len(args) != 1 {
+			return fmt.Errorf("usage: -migrate down N")
+		}
+		n, err := strconv.Atoi(args[0])
+		if err != nil {
+			return fmt.Errorf("invalid migration count %q", args[0])
+		}
+		return runner.Down(n)
+
+	case "version":
+		version, dirty, err := runner.Version()
+		if err != nil {
+			return err
+		}
+		fmt.Printf("version %d (dirty=%t)\n", version, dirty)
+		return nil
+
+	case "force":
+		if len(args) != 1 {
+			return fmt.Errorf("usage: -migrate force V")
+		}
+		v, err := strconv.ParseInt(args[0], 10, 64)
+		if err != nil {
+			return fmt.Errorf("invalid version %q", args[0])
+		}
+		return runner.Force(v)
+
+	default:
+		return fmt.Errorf("unknown migrate command %q (want up, down, version, or force)", cmd)
+	}
+}
@@ -0,0 +1,261 @@
+// Package migrations applies the Postgres schema as an ordered sequence of
+// up/down SQL files, tracked in a schema_migrations table, instead of the
+// ad-hoc CREATE TABLE IF NOT EXISTS calls PostgresStore.Init used to make.
+// New schema changes land here as a new numbered pair of files rather than
+// as edits to existing ones.
+package migrations
+
+import (
+	"database/sql"
+	"embed"
+	"fmt"
+	"io/fs"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+//go:embed sql/*.sql
+var sqlFS embed.FS
+
+// advisoryLockID is an arbitrary fixed key for pg_advisory_lock, scoping the
+// lock to "a migration is running" rather than any particular table.
+const advisoryLockID = 8_746_201_953
+
+type migration struct {
+	version int64
+	name    string
+	up      string
+	down    string
+}
+
+// Runner applies and rolls back the embedded migrations against a Postgres
+// database, serializing concurrent runs with a session-level advisory lock.
+type Runner struct {
+	db         *sql.DB
+	migrations []migration
+}
+
+func New(db *sql.DB) (*Runner, error) {
+	migs, err := loadMigrations()
+	if err != nil {
+		return nil, err
+	}
+
+	if _, err := db.Exec(`CREATE TABLE IF NOT EXISTS schema_migrations (
+		version BIGINT PRIMARY KEY,
+		dirty BOOLEAN NOT NULL DEFAULT false
+	)`); err != nil {
+		return nil, err
+	}
+
+	return &Runner{db: db, migrations: migs}, nil
+}
+
+func loadMigrations() ([]migration, error) {
+	entries, err := fs.ReadDir(sqlFS, "sql")
+	if err != nil {
+		return nil, err
+	}
+
+	byVersion := map[int64]*migration{}
+	for _, entry := range entries {
+		name := entry.Name()
+		version, label, direction, err := parseFilename(name)
+		if err != nil {
+			return nil, fmt.Errorf("migrations: %w", err)
+		}
+
+		contents, err := sqlFS.ReadFile("sql/" + name)
+		if err != nil {
+			return nil, err
+		}
+
+		m, ok := byVersion[version]
+		if !ok {
+			m = &migration{version: version, name: label}
+			byVersion[version] = m
+		}
+		switch direction {
+		case "up":
+			m.up = string(contents)
+		case "down":
+			m.down = string(contents)
+		}
+	}
+
+	migs := make([]migration, 0, len(byVersion))
+	for _, m := range byVersion {
+		if m.up == "" {
+			return nil, fmt.Errorf("migrations: version %04d has no .up.sql file", m.version)
+		}
+		migs = append(migs, *m)
+	}
+	sort.Slice(migs, func(i, j int) bool { return migs[i].version < migs[j].version })
+
+	return migs, nil
+}
+
+// parseFilename splits "0002_add_account_types.up.sql" into its version,
+// name, and direction.
+func parseFilename(name string) (version int64, label string, direction string, err error) {
+	base := strings.TrimSuffix(name, ".sql")
+	base, direction, ok := cutSuffix(base, ".up")
+	if !ok {
+		var ok2 bool
+		base, direction, ok2 = cutSuffix(base, ".down")
+		if !ok2 {
+			return 0, "", "", fmt.Errorf("%q does not end in .up.sql or .down.sql", name)
+		}
+	}
+
+	versionStr, label, ok := strings.Cut(base, "_")
+	if !ok {
+		return 0, "", "", fmt.Errorf("%q is missing a _ separating version from name", name)
+	}
+
+	version, err = strconv.ParseInt(versionStr, 10, 64)
+	if err != nil {
+		return 0, "", "", fmt.Errorf("%q has a non-numeric version: %w", name, err)
+	}
+
+	return version, label, direction, nil
+}
+
+func cutSuffix(s, suffix string) (before, dir string, ok bool) {
+	if !strings.HasSuffix(s, suffix) {
+		return s, "", false
+	}
+	return strings.TrimSuffix(s, suffix), strings.TrimPrefix(suffix, "."), true
+}
+
+func (r *Runner) withLock(fn func() error) error {
+	if _, err := r.db.Exec("SELECT pg_advisory_lock($1)", advisoryLockID); err != nil {
+		return err
+	}
+	defer r.db.Exec("SELECT pg_advisory_unlock($1)", advisoryLockID)
+
+	return fn()
+}
+
+// Version reports the highest applied migration version and whether the
+// last attempted migration left the schema dirty (failed partway through).
+func (r *Runner) Version() (version int64, dirty bool, err error) {
+	err = r.db.QueryRow("SELECT version, dirty FROM schema_migrations ORDER BY version DESC LIMIT 1").Scan(&version, &dirty)
+	if err == sql.ErrNoRows {
+		return 0, false, nil
+	}
+	return version, dirty, err
+}
+
+// Up applies every migration newer than the current version, in order.
+func (r *Runner) Up() error {
+	return r.withLock(func() error {
+		current, dirty, err := r.Version()
+		if err != nil {
+			return err
+		}
+		if dirty {
+			return fmt.Errorf("migrations: schema is dirty at version %d, fix it and run -migrate force V", current)
+		}
+
+		for _, m := range r.migrations {
+			if m.version <= current {
+				continue
+			}
+			if err := r.apply(m, m.up); err != nil {
+				return fmt.Errorf("migrations: applying %04d_%s: %w", m.version, m.name, err)
+			}
+		}
+		return nil
+	})
+}
+
+// Down rolls back the n most recently applied migrations, in reverse order.
+func (r *Runner) Down(n int) error {
+	return r.withLock(func() error {
+		current, dirty, err := r.Version()
+		if err != nil {
+			return err
+		}
+		if dirty {
+			return fmt.Errorf("migrations: schema is dirty at version %d, fix it and run -migrate force V", current)
+		}
+
+		applied := make([]migration, 0, len(r.migrations))
+		for _, m := range r.migrations {
+			if m.version <= current {
+				applied = append(applied, m)
+			}
+		}
+		sort.Slice(applied, func(i, j int) bool { return applied[i].version > applied[j].version })
+
+		if n > len(applied) {
+			n = len(applied)
+		}
+
+		for _, m := range applied[:n] {
+			if m.down == "" {
+				return fmt.Errorf("migrations: version %04d_%s has no .down.sql file", m.version, m.name)
+			}
+			if err := r.revert(m); err != nil {
+				return fmt.Errorf("migrations: reverting %04d_%s: %w", m.version, m.name, err)
+			}
+		}
+		return nil
+	})
+}
+
+// Force sets the recorded version to v and clears the dirty flag, without
+// running any migration SQL. It exists to recover from a schema a failed
+// migration left dirty: fix the schema by hand, then force it to the
+// version that actually matches reality.
+func (r *Runner) Force(v int64) error {
+	return r.withLock(func() error {
+		_, err := r.db.Exec(`INSERT INTO schema_migrations (version, dirty) VALUES ($1, false)
+			ON CONFLICT (version) DO UPDATE SET dirty = false`, v)
+		if err != nil {
+			return err
+		}
+		_, err = r.db.Exec(`DELETE FROM schema_migrations WHERE version != $1`, v)
+		return err
+	})
+}
+
+func (r *Runner) apply(m migration, query string) error {
+	if _, err := r.db.Exec(`INSERT INTO schema_migrations (version, dirty) VALUES ($1, true)
+		ON CONFLICT (version) DO UPDATE SET dirty = true`, m.version); err != nil {
+		return err
+	}
+
+	tx, err := r.db.Begin()
+	if err != nil {
+		return err
+	}
+	if _, err := tx.Exec(query); err != nil {
+		tx.Rollback()
+		return err
+	}
+	if err := tx.Commit(); err != nil {
+		return err
+	}
+
+	_, err = r.db.Exec(`UPDATE schema_migrations SET dirty = false WHERE version = $1`, m.version)
+	return err
+}
+
+func (r *Runner) revert(m migration) error {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return err
+	}
+	if _, err := tx.Exec(m.down); err != nil {
+		tx.Rollback()
+		return err
+	}
+	if _, err := tx.Exec(`DELETE FROM schema_migrations WHERE version = $1`, m.version); err != nil {
+		tx.Rollback()
+		return err
+	}
+	return tx.Commit()
+}
@@ -0,0 +1,36 @@
+package migrations
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLoadMigrations(t *testing.T) {
+	migs, err := loadMigrations()
+	require.NoError(t, err)
+	require.Len(t, migs, 5)
+
+	for i, m := range migs {
+		assert.NotEmpty(t, m.up, "migration %04d is missing up SQL", m.version)
+		assert.NotEmpty(t, m.down, "migration %04d is missing down SQL", m.version)
+		if i > 0 {
+			assert.Greater(t, m.version, migs[i-1].version, "migrations must be sorted ascending by version")
+		}
+	}
+
+	assert.Equal(t, int64(1), migs[0].version)
+	assert.Equal(t, "init", migs[0].name)
+}
+
+func TestParseFilename(t *testing.T) {
+	version, name, direction, err := parseFilename("0002_add_account_types.up.sql")
+	require.NoError(t, err)
+	assert.Equal(t, int64(2), version)
+	assert.Equal(t, "add_account_types", name)
+	assert.Equal(t, "up", direction)
+
+	_, _, _, err = parseFilename("bogus.sql")
+	assert.Error(t, err)
+}
@@ -0,0 +1,56 @@
+//go:build postgres
+
+// Exercises Runner against a live database, so it only builds/runs with
+// `go test -tags postgres ./...` against a reachable DATABASE_URL/PG_CONN.
+package migrations
+
+import (
+	"database/sql"
+	"os"
+	"testing"
+
+	_ "github.com/lib/pq"
+	"github.com/stretchr/testify/require"
+)
+
+func openTestDB(t *testing.T) *sql.DB {
+	connStr := os.Getenv("DATABASE_URL")
+	if connStr == "" {
+		connStr = os.Getenv("PG_CONN")
+	}
+	if connStr == "" {
+		connStr = "user=postgres dbname=postgres password=gobank sslmode=disable"
+	}
+
+	db, err := sql.Open("postgres", connStr)
+	require.NoError(t, err)
+	t.Cleanup(func() { db.Close() })
+	return db
+}
+
+func TestRunnerUpDownForce(t *testing.T) {
+	db := openTestDB(t)
+
+	_, err := db.Exec("DROP TABLE IF EXISTS sessions, splits, transactions, account, schema_migrations")
+	require.NoError(t, err)
+
+	runner, err := New(db)
+	require.NoError(t, err)
+
+	require.NoError(t, runner.Up())
+	version, dirty, err := runner.Version()
+	require.NoError(t, err)
+	require.False(t, dirty)
+	require.Equal(t, int64(5), version)
+
+	require.NoError(t, runner.Down(1))
+	version, _, err = runner.Version()
+	require.NoError(t, err)
+	require.Equal(t, int64(4), version)
+
+	require.NoError(t, runner.Force(1))
+	version, dirty, err = runner.Version()
+	require.NoError(t, err)
+	require.False(t, dirty)
+	require.Equal(t, int64(1), version)
+}
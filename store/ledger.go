@@ -0,0 +1,53 @@
+package store
+
+import (
+	"fmt"
+	"time"
+)
+
+// Split is one leg of a double-entry Transaction. Amount is signed and
+// denominated in minor units (cents); debits and credits to the same
+// account are just positive and negative amounts on the same AccountID.
+type Split struct {
+	ID            int    `json:"id"`
+	TransactionID int    `json:"transactionId"`
+	AccountID     int    `json:"accountId"`
+	Amount        int64  `json:"amount"`
+	Memo          string `json:"memo,omitempty"`
+}
+
+// Transaction is a balanced set of splits posted to the ledger atomically.
+// A valid Transaction always has sum(Splits[i].Amount) == 0.
+type Transaction struct {
+	ID          int       `json:"id"`
+	Description string    `json:"description"`
+	PostedAt    time.Time `json:"postedAt"`
+	Splits      []*Split  `json:"splits"`
+}
+
+// Balanced reports whether t's splits sum to zero, as double-entry
+// bookkeeping requires.
+func (t *Transaction) Balanced() bool {
+	var total int64
+	for _, split := range t.Splits {
+		total += split.Amount
+	}
+	return total == 0
+}
+
+// NewTransfer builds the balanced two-split transaction that moves amount
+// (in minor units) from fromAccountID to toAccountID.
+func NewTransfer(fromAccountID, toAccountID int, amount int64, description string) (*Transaction, error) {
+	if amount <= 0 {
+		return nil, fmt.Errorf("invalid transfer amount")
+	}
+
+	return &Transaction{
+		Description: description,
+		PostedAt:    time.Now().UTC(),
+		Splits: []*Split{
+			{AccountID: fromAccountID, Amount: -amount},
+			{AccountID: toAccountID, Amount: amount},
+		},
+	}, nil
+}
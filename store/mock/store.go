@@ -0,0 +1,285 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: github.com/AbhiAnand-1011/siuu-bank/store (interfaces: Storage)
+//
+// Generated by this command:
+//
+//	mockgen -package mockstore -destination mock/store.go . Storage
+//
+
+// Package mockstore is a generated GoMock package.
+package mockstore
+
+import (
+	reflect "reflect"
+
+	store "github.com/AbhiAnand-1011/siuu-bank/store"
+	gomock "go.uber.org/mock/gomock"
+)
+
+// MockStorage is a mock of Storage interface.
+type MockStorage struct {
+	ctrl     *gomock.Controller
+	recorder *MockStorageMockRecorder
+}
+
+// MockStorageMockRecorder is the mock recorder for MockStorage.
+type MockStorageMockRecorder struct {
+	mock *MockStorage
+}
+
+// NewMockStorage creates a new mock instance.
+func NewMockStorage(ctrl *gomock.Controller) *MockStorage {
+	mock := &MockStorage{ctrl: ctrl}
+	mock.recorder = &MockStorageMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockStorage) EXPECT() *MockStorageMockRecorder {
+	return m.recorder
+}
+
+// CreateAccount mocks base method.
+func (m *MockStorage) CreateAccount(arg0 *store.Account) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateAccount", arg0)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// CreateAccount indicates an expected call of CreateAccount.
+func (mr *MockStorageMockRecorder) CreateAccount(arg0 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateAccount", reflect.TypeOf((*MockStorage)(nil).CreateAccount), arg0)
+}
+
+// CreateChildAccount mocks base method.
+func (m *MockStorage) CreateChildAccount(arg0 int, arg1 *store.Account) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateChildAccount", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// CreateChildAccount indicates an expected call of CreateChildAccount.
+func (mr *MockStorageMockRecorder) CreateChildAccount(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateChildAccount", reflect.TypeOf((*MockStorage)(nil).CreateChildAccount), arg0, arg1)
+}
+
+// CreateSession mocks base method.
+func (m *MockStorage) CreateSession(arg0 *store.Session) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateSession", arg0)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// CreateSession indicates an expected call of CreateSession.
+func (mr *MockStorageMockRecorder) CreateSession(arg0 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateSession", reflect.TypeOf((*MockStorage)(nil).CreateSession), arg0)
+}
+
+// DeleteAccount mocks base method.
+func (m *MockStorage) DeleteAccount(arg0 int) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteAccount", arg0)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteAccount indicates an expected call of DeleteAccount.
+func (mr *MockStorageMockRecorder) DeleteAccount(arg0 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteAccount", reflect.TypeOf((*MockStorage)(nil).DeleteAccount), arg0)
+}
+
+// GetAccountByID mocks base method.
+func (m *MockStorage) GetAccountByID(arg0 int) (*store.Account, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetAccountByID", arg0)
+	ret0, _ := ret[0].(*store.Account)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetAccountByID indicates an expected call of GetAccountByID.
+func (mr *MockStorageMockRecorder) GetAccountByID(arg0 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetAccountByID", reflect.TypeOf((*MockStorage)(nil).GetAccountByID), arg0)
+}
+
+// GetAccountByNumber mocks base method.
+func (m *MockStorage) GetAccountByNumber(arg0 int) (*store.Account, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetAccountByNumber", arg0)
+	ret0, _ := ret[0].(*store.Account)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetAccountByNumber indicates an expected call of GetAccountByNumber.
+func (mr *MockStorageMockRecorder) GetAccountByNumber(arg0 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetAccountByNumber", reflect.TypeOf((*MockStorage)(nil).GetAccountByNumber), arg0)
+}
+
+// GetAccountTree mocks base method.
+func (m *MockStorage) GetAccountTree(arg0 int) (*store.AccountNode, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetAccountTree", arg0)
+	ret0, _ := ret[0].(*store.AccountNode)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetAccountTree indicates an expected call of GetAccountTree.
+func (mr *MockStorageMockRecorder) GetAccountTree(arg0 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetAccountTree", reflect.TypeOf((*MockStorage)(nil).GetAccountTree), arg0)
+}
+
+// GetAccounts mocks base method.
+func (m *MockStorage) GetAccounts() ([]*store.Account, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetAccounts")
+	ret0, _ := ret[0].([]*store.Account)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetAccounts indicates an expected call of GetAccounts.
+func (mr *MockStorageMockRecorder) GetAccounts() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetAccounts", reflect.TypeOf((*MockStorage)(nil).GetAccounts))
+}
+
+// GetLedger mocks base method.
+func (m *MockStorage) GetLedger(arg0 int, arg1 int64) ([]*store.Split, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetLedger", arg0, arg1)
+	ret0, _ := ret[0].([]*store.Split)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetLedger indicates an expected call of GetLedger.
+func (mr *MockStorageMockRecorder) GetLedger(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetLedger", reflect.TypeOf((*MockStorage)(nil).GetLedger), arg0, arg1)
+}
+
+// GetSessionByID mocks base method.
+func (m *MockStorage) GetSessionByID(arg0 string) (*store.Session, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetSessionByID", arg0)
+	ret0, _ := ret[0].(*store.Session)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetSessionByID indicates an expected call of GetSessionByID.
+func (mr *MockStorageMockRecorder) GetSessionByID(arg0 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetSessionByID", reflect.TypeOf((*MockStorage)(nil).GetSessionByID), arg0)
+}
+
+// Init mocks base method.
+func (m *MockStorage) Init() error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Init")
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Init indicates an expected call of Init.
+func (mr *MockStorageMockRecorder) Init() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Init", reflect.TypeOf((*MockStorage)(nil).Init))
+}
+
+// ListAccountsByType mocks base method.
+func (m *MockStorage) ListAccountsByType(arg0 int, arg1 store.AccountType) ([]*store.Account, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListAccountsByType", arg0, arg1)
+	ret0, _ := ret[0].([]*store.Account)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListAccountsByType indicates an expected call of ListAccountsByType.
+func (mr *MockStorageMockRecorder) ListAccountsByType(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListAccountsByType", reflect.TypeOf((*MockStorage)(nil).ListAccountsByType), arg0, arg1)
+}
+
+// PostTransaction mocks base method.
+func (m *MockStorage) PostTransaction(arg0 *store.Transaction) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "PostTransaction", arg0)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// PostTransaction indicates an expected call of PostTransaction.
+func (mr *MockStorageMockRecorder) PostTransaction(arg0 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "PostTransaction", reflect.TypeOf((*MockStorage)(nil).PostTransaction), arg0)
+}
+
+// RevokeAllSessions mocks base method.
+func (m *MockStorage) RevokeAllSessions(arg0 int) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RevokeAllSessions", arg0)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// RevokeAllSessions indicates an expected call of RevokeAllSessions.
+func (mr *MockStorageMockRecorder) RevokeAllSessions(arg0 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RevokeAllSessions", reflect.TypeOf((*MockStorage)(nil).RevokeAllSessions), arg0)
+}
+
+// RevokeSession mocks base method.
+func (m *MockStorage) RevokeSession(arg0 string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RevokeSession", arg0)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// RevokeSession indicates an expected call of RevokeSession.
+func (mr *MockStorageMockRecorder) RevokeSession(arg0 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RevokeSession", reflect.TypeOf((*MockStorage)(nil).RevokeSession), arg0)
+}
+
+// SetAccountRole mocks base method.
+func (m *MockStorage) SetAccountRole(arg0 int, arg1 store.Role) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SetAccountRole", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SetAccountRole indicates an expected call of SetAccountRole.
+func (mr *MockStorageMockRecorder) SetAccountRole(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetAccountRole", reflect.TypeOf((*MockStorage)(nil).SetAccountRole), arg0, arg1)
+}
+
+// UpdateAccount mocks base method.
+func (m *MockStorage) UpdateAccount(arg0 *store.Account) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateAccount", arg0)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UpdateAccount indicates an expected call of UpdateAccount.
+func (mr *MockStorageMockRecorder) UpdateAccount(arg0 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateAccount", reflect.TypeOf((*MockStorage)(nil).UpdateAccount), arg0)
+}
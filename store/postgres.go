@@ -0,0 +1,500 @@
+package store
+
+import (
+	"database/sql"
+	"fmt"
+	"os"
+	"sort"
+	"strconv"
+
+	"github.com/AbhiAnand-1011/siuu-bank/migrations"
+	_ "github.com/lib/pq"
+)
+
+type PostgresStore struct {
+	db *sql.DB
+
+	// overdraftLimit is how far (in minor units) a bank/cash/asset/liability
+	// account may be driven below zero by a single transaction before
+	// PostTransaction rejects it with ErrInsufficientFunds.
+	overdraftLimit int64
+}
+
+func NewPostgresStore() (*PostgresStore, error) {
+	connStr := os.Getenv("DATABASE_URL")
+	if connStr == "" {
+		connStr = os.Getenv("PG_CONN")
+	}
+	if connStr == "" {
+		connStr = "user=postgres dbname=postgres password=gobank sslmode=disable"
+	}
+
+	db, err := sql.Open("postgres", connStr)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := db.Ping(); err != nil {
+		return nil, err
+	}
+
+	var overdraftLimit int64
+	if raw := os.Getenv("OVERDRAFT_LIMIT"); raw != "" {
+		overdraftLimit, err = strconv.ParseInt(raw, 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid OVERDRAFT_LIMIT: %w", err)
+		}
+	}
+
+	return &PostgresStore{
+		db:             db,
+		overdraftLimit: overdraftLimit,
+	}, nil
+}
+
+// Init brings the schema up to date by running every pending migration
+// under migrations.Runner. See that package for how versions, dirty state,
+// and the advisory lock work.
+func (s *PostgresStore) Init() error {
+	runner, err := migrations.New(s.db)
+	if err != nil {
+		return err
+	}
+	return runner.Up()
+}
+
+// DB exposes the underlying connection so the -migrate CLI can run a
+// migrations.Runner directly, without going through the Storage interface.
+func (s *PostgresStore) DB() *sql.DB {
+	return s.db
+}
+
+func (s *PostgresStore) CreateAccount(acc *Account) error {
+	if acc.Type == "" {
+		acc.Type = AccountTypeBank
+	}
+
+	if acc.Role == "" {
+		acc.Role = RoleUser
+	}
+
+	query := `INSERT INTO account
+	(first_name, last_name, number, encrypted_password, balance, type, parent_id, version, created_at, role)
+	VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)
+	RETURNING id`
+
+	return s.db.QueryRow(
+		query,
+		acc.FirstName,
+		acc.LastName,
+		acc.Number,
+		acc.EncryptedPassword,
+		acc.Balance,
+		acc.Type,
+		acc.ParentAccountID,
+		acc.AccountVersion,
+		acc.CreatedAt,
+		acc.Role,
+	).Scan(&acc.ID)
+}
+
+// CreateChildAccount inserts acc as a sub-account of parentID, verifying
+// the parent exists first so callers get a clear error instead of relying
+// solely on the self-referential foreign key.
+func (s *PostgresStore) CreateChildAccount(parentID int, acc *Account) error {
+	if _, err := s.GetAccountByID(parentID); err != nil {
+		return fmt.Errorf("parent account %d not found: %w", parentID, err)
+	}
+
+	acc.ParentAccountID = &parentID
+	return s.CreateAccount(acc)
+}
+
+func (s *PostgresStore) UpdateAccount(acc *Account) error {
+	query := `UPDATE account SET
+		first_name = $1,
+		last_name = $2,
+		encrypted_password = $3,
+		balance = $4
+		WHERE id = $5`
+
+	_, err := s.db.Exec(
+		query,
+		acc.FirstName,
+		acc.LastName,
+		acc.EncryptedPassword,
+		acc.Balance,
+		acc.ID,
+	)
+
+	return err
+}
+
+func (s *PostgresStore) DeleteAccount(id int) error {
+	_, err := s.db.Exec("DELETE FROM account WHERE id = $1", id)
+	return err
+}
+
+func (s *PostgresStore) SetAccountRole(id int, role Role) error {
+	if !role.Valid() {
+		return fmt.Errorf("invalid role %q", role)
+	}
+	_, err := s.db.Exec("UPDATE account SET role = $1 WHERE id = $2", role, id)
+	return err
+}
+
+func (s *PostgresStore) GetAccountByNumber(number int) (*Account, error) {
+	account := new(Account)
+	query := `SELECT id, first_name, last_name, number, encrypted_password, balance, type, parent_id, version, created_at, role
+		FROM account
+		WHERE number = $1`
+
+	err := s.db.QueryRow(query, number).Scan(
+		&account.ID,
+		&account.FirstName,
+		&account.LastName,
+		&account.Number,
+		&account.EncryptedPassword,
+		&account.Balance,
+		&account.Type,
+		&account.ParentAccountID,
+		&account.AccountVersion,
+		&account.CreatedAt,
+		&account.Role,
+	)
+	if err == sql.ErrNoRows {
+		return nil, fmt.Errorf("account with number [%d] not found", number)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return account, nil
+}
+
+func (s *PostgresStore) GetAccountByID(id int) (*Account, error) {
+	account := new(Account)
+	query := `SELECT id, first_name, last_name, number, encrypted_password, balance, type, parent_id, version, created_at, role
+		FROM account
+		WHERE id = $1`
+
+	err := s.db.QueryRow(query, id).Scan(
+		&account.ID,
+		&account.FirstName,
+		&account.LastName,
+		&account.Number,
+		&account.EncryptedPassword,
+		&account.Balance,
+		&account.Type,
+		&account.ParentAccountID,
+		&account.AccountVersion,
+		&account.CreatedAt,
+		&account.Role,
+	)
+	if err == sql.ErrNoRows {
+		return nil, fmt.Errorf("account %d not found", id)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return account, nil
+}
+
+func (s *PostgresStore) GetAccounts() ([]*Account, error) {
+	rows, err := s.db.Query(
+		"SELECT id, first_name, last_name, number, encrypted_password, balance, type, parent_id, version, created_at, role FROM account",
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	accounts := []*Account{}
+	for rows.Next() {
+		account, err := scanIntoAccount(rows)
+		if err != nil {
+			return nil, err
+		}
+		accounts = append(accounts, account)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return accounts, nil
+}
+
+// GetAccountTree walks the chart of accounts rooted at rootID and returns
+// it as a nested tree, using a recursive CTE so the whole subtree is
+// fetched in one round trip regardless of depth.
+func (s *PostgresStore) GetAccountTree(rootID int) (*AccountNode, error) {
+	query := `WITH RECURSIVE subtree AS (
+		SELECT id, first_name, last_name, number, encrypted_password, balance, type, parent_id, version, created_at, role
+		FROM account WHERE id = $1
+		UNION ALL
+		SELECT a.id, a.first_name, a.last_name, a.number, a.encrypted_password, a.balance, a.type, a.parent_id, a.version, a.created_at, a.role
+		FROM account a JOIN subtree s ON a.parent_id = s.id
+	)
+	SELECT id, first_name, last_name, number, encrypted_password, balance, type, parent_id, version, created_at, role FROM subtree`
+
+	rows, err := s.db.Query(query, rootID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	nodes := map[int]*AccountNode{}
+	var order []int
+	for rows.Next() {
+		account, err := scanIntoAccount(rows)
+		if err != nil {
+			return nil, err
+		}
+		nodes[account.ID] = &AccountNode{AccountView: account.View()}
+		order = append(order, account.ID)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	root, ok := nodes[rootID]
+	if !ok {
+		return nil, fmt.Errorf("account %d not found", rootID)
+	}
+
+	for _, id := range order {
+		node := nodes[id]
+		if node.ParentAccountID == nil || id == rootID {
+			continue
+		}
+		parent, ok := nodes[*node.ParentAccountID]
+		if !ok {
+			continue
+		}
+		parent.Children = append(parent.Children, node)
+	}
+
+	return root, nil
+}
+
+// ListAccountsByType returns every account of accType within the chart of
+// accounts rooted at rootID.
+func (s *PostgresStore) ListAccountsByType(rootID int, accType AccountType) ([]*Account, error) {
+	query := `WITH RECURSIVE subtree AS (
+		SELECT id FROM account WHERE id = $1
+		UNION ALL
+		SELECT a.id FROM account a JOIN subtree s ON a.parent_id = s.id
+	)
+	SELECT a.id, a.first_name, a.last_name, a.number, a.encrypted_password, a.balance, a.type, a.parent_id, a.version, a.created_at, a.role
+	FROM account a JOIN subtree s ON a.id = s.id
+	WHERE a.type = $2`
+
+	rows, err := s.db.Query(query, rootID, accType)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	accounts := []*Account{}
+	for rows.Next() {
+		account, err := scanIntoAccount(rows)
+		if err != nil {
+			return nil, err
+		}
+		accounts = append(accounts, account)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return accounts, nil
+}
+
+// PostTransaction applies t's splits to their accounts atomically. It
+// verifies the entry is balanced, locks every involved account in
+// ascending-ID order (the same deadlock-avoiding ordering the old
+// two-account Transfer used), and rejects the whole entry with
+// ErrInsufficientFunds if any overdraft-checked account would be driven
+// below its configured overdraft limit.
+func (s *PostgresStore) PostTransaction(t *Transaction) error {
+	if len(t.Splits) == 0 {
+		return fmt.Errorf("transaction has no splits")
+	}
+	if !t.Balanced() {
+		return fmt.Errorf("unbalanced transaction: splits must sum to zero")
+	}
+
+	tx, err := s.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	type accRow struct {
+		id      int
+		balance int64
+		accType AccountType
+	}
+
+	accountIDs := make([]int, 0, len(t.Splits))
+	seen := map[int]bool{}
+	for _, split := range t.Splits {
+		if !seen[split.AccountID] {
+			seen[split.AccountID] = true
+			accountIDs = append(accountIDs, split.AccountID)
+		}
+	}
+	sort.Ints(accountIDs)
+
+	accounts := make(map[int]*accRow, len(accountIDs))
+	for _, id := range accountIDs {
+		row := tx.QueryRow("SELECT id, balance, type FROM account WHERE id = $1 FOR UPDATE", id)
+		var a accRow
+		if err := row.Scan(&a.id, &a.balance, &a.accType); err != nil {
+			if err == sql.ErrNoRows {
+				return fmt.Errorf("account %d not found", id)
+			}
+			return err
+		}
+		accounts[id] = &a
+	}
+
+	newBalances := map[int]int64{}
+	for id, a := range accounts {
+		newBalances[id] = a.balance
+	}
+	for _, split := range t.Splits {
+		newBalances[split.AccountID] += split.Amount
+	}
+	for id, balance := range newBalances {
+		accType := accounts[id].accType
+		if requiresOverdraftCheck(accType) && balance < -s.overdraftLimit {
+			return ErrInsufficientFunds
+		}
+	}
+
+	query := `INSERT INTO transactions (description, posted_at) VALUES ($1, $2) RETURNING id`
+	if err := tx.QueryRow(query, t.Description, t.PostedAt).Scan(&t.ID); err != nil {
+		return err
+	}
+
+	for _, split := range t.Splits {
+		id := split.AccountID
+		if _, err := tx.Exec(
+			"UPDATE account SET balance = balance + $1, version = version + 1 WHERE id = $2",
+			split.Amount,
+			id,
+		); err != nil {
+			return err
+		}
+
+		var version int64
+		if err := tx.QueryRow("SELECT version FROM account WHERE id = $1", id).Scan(&version); err != nil {
+			return err
+		}
+		split.TransactionID = t.ID
+
+		if err := tx.QueryRow(
+			`INSERT INTO splits (transaction_id, account_id, amount, memo, account_version)
+			VALUES ($1, $2, $3, $4, $5) RETURNING id`,
+			split.TransactionID,
+			split.AccountID,
+			split.Amount,
+			split.Memo,
+			version,
+		).Scan(&split.ID); err != nil {
+			return err
+		}
+	}
+
+	return tx.Commit()
+}
+
+// GetLedger returns the splits posted to accountID since the client last
+// saw the account at AccountVersion since, ordered oldest-first so a
+// client can page through by remembering the last version it processed.
+func (s *PostgresStore) GetLedger(accountID int, since int64) ([]*Split, error) {
+	rows, err := s.db.Query(
+		`SELECT id, transaction_id, account_id, amount, memo, account_version
+		FROM splits
+		WHERE account_id = $1 AND account_version > $2
+		ORDER BY account_version ASC`,
+		accountID,
+		since,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	splits := []*Split{}
+	for rows.Next() {
+		split := new(Split)
+		var version int64
+		if err := rows.Scan(&split.ID, &split.TransactionID, &split.AccountID, &split.Amount, &split.Memo, &version); err != nil {
+			return nil, err
+		}
+		splits = append(splits, split)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return splits, nil
+}
+
+func (s *PostgresStore) CreateSession(session *Session) error {
+	query := `INSERT INTO sessions
+	(id, account_id, refresh_token_hash, expires_at, user_agent, ip)
+	VALUES ($1, $2, $3, $4, $5, $6)`
+
+	_, err := s.db.Exec(
+		query,
+		session.ID,
+		session.AccountID,
+		session.RefreshTokenHash,
+		session.ExpiresAt,
+		session.UserAgent,
+		session.IP,
+	)
+	return err
+}
+
+func (s *PostgresStore) GetSessionByID(id string) (*Session, error) {
+	session := new(Session)
+	query := `SELECT id, account_id, refresh_token_hash, expires_at, revoked_at, user_agent, ip
+		FROM sessions
+		WHERE id = $1`
+
+	err := s.db.QueryRow(query, id).Scan(
+		&session.ID,
+		&session.AccountID,
+		&session.RefreshTokenHash,
+		&session.ExpiresAt,
+		&session.RevokedAt,
+		&session.UserAgent,
+		&session.IP,
+	)
+	if err == sql.ErrNoRows {
+		return nil, fmt.Errorf("session %s not found", id)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return session, nil
+}
+
+func (s *PostgresStore) RevokeSession(id string) error {
+	_, err := s.db.Exec(`UPDATE sessions SET revoked_at = now() WHERE id = $1 AND revoked_at IS NULL`, id)
+	return err
+}
+
+func (s *PostgresStore) RevokeAllSessions(accountID int) error {
+	_, err := s.db.Exec(`UPDATE sessions SET revoked_at = now() WHERE account_id = $1 AND revoked_at IS NULL`, accountID)
+	return err
+}
@@ -0,0 +1,21 @@
+//go:build postgres
+
+// Postgres requires a live database, so this file only builds/runs with
+// `go test -tags postgres ./...` against a reachable DATABASE_URL/PG_CONN.
+package store_test
+
+import (
+	"testing"
+
+	"github.com/AbhiAnand-1011/siuu-bank/store"
+	"github.com/AbhiAnand-1011/siuu-bank/store/storagetest"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPostgresStore(t *testing.T) {
+	s, err := store.NewPostgresStore()
+	require.NoError(t, err)
+	require.NoError(t, s.Init())
+
+	storagetest.Run(t, s)
+}
@@ -0,0 +1,565 @@
+package store
+
+import (
+	"database/sql"
+	"fmt"
+	"os"
+	"sort"
+	"strconv"
+	"time"
+
+	_ "modernc.org/sqlite"
+)
+
+// SQLiteStore is a single-file backend meant for local development and the
+// storagetest suite. It implements the same Storage interface as
+// PostgresStore, translating the handful of places Postgres and SQLite
+// syntax diverge (placeholders, locking, RETURNING support).
+type SQLiteStore struct {
+	db *sql.DB
+
+	overdraftLimit int64
+}
+
+// NewSQLiteStore opens the database file named by SQLITE_PATH (defaulting
+// to a throwaway file in the working directory). SQLite only allows one
+// writer at a time, so we cap the pool at a single connection and rely on
+// BEGIN IMMEDIATE to serialize writers instead of row-level locks.
+func NewSQLiteStore() (*SQLiteStore, error) {
+	path := os.Getenv("SQLITE_PATH")
+	if path == "" {
+		path = "siuu-bank.db"
+	}
+
+	db, err := sql.Open("sqlite", path)
+	if err != nil {
+		return nil, err
+	}
+	db.SetMaxOpenConns(1)
+
+	if err := db.Ping(); err != nil {
+		return nil, err
+	}
+
+	if _, err := db.Exec("PRAGMA foreign_keys = ON"); err != nil {
+		return nil, err
+	}
+
+	var overdraftLimit int64
+	if raw := os.Getenv("OVERDRAFT_LIMIT"); raw != "" {
+		overdraftLimit, err = strconv.ParseInt(raw, 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid OVERDRAFT_LIMIT: %w", err)
+		}
+	}
+
+	return &SQLiteStore{
+		db:             db,
+		overdraftLimit: overdraftLimit,
+	}, nil
+}
+
+func (s *SQLiteStore) Init() error {
+	if err := s.createAccountTable(); err != nil {
+		return err
+	}
+	if err := s.createLedgerTables(); err != nil {
+		return err
+	}
+	return s.createSessionTable()
+}
+
+func (s *SQLiteStore) createAccountTable() error {
+	query := `CREATE TABLE IF NOT EXISTS account (
+		id INTEGER PRIMARY KEY AUTOINCREMENT,
+		first_name TEXT,
+		last_name TEXT,
+		number INTEGER UNIQUE NOT NULL,
+		encrypted_password TEXT,
+		balance INTEGER NOT NULL DEFAULT 0,
+		type TEXT NOT NULL DEFAULT 'bank',
+		parent_id INTEGER REFERENCES account(id),
+		version INTEGER NOT NULL DEFAULT 0,
+		created_at DATETIME NOT NULL,
+		role TEXT NOT NULL DEFAULT 'user'
+	)`
+	_, err := s.db.Exec(query)
+	return err
+}
+
+func (s *SQLiteStore) createLedgerTables() error {
+	query := `CREATE TABLE IF NOT EXISTS transactions (
+		id INTEGER PRIMARY KEY AUTOINCREMENT,
+		description TEXT,
+		posted_at DATETIME NOT NULL
+	)`
+	if _, err := s.db.Exec(query); err != nil {
+		return err
+	}
+
+	query = `CREATE TABLE IF NOT EXISTS splits (
+		id INTEGER PRIMARY KEY AUTOINCREMENT,
+		transaction_id INTEGER NOT NULL REFERENCES transactions(id),
+		account_id INTEGER NOT NULL REFERENCES account(id),
+		amount INTEGER NOT NULL,
+		memo TEXT,
+		account_version INTEGER NOT NULL
+	)`
+	if _, err := s.db.Exec(query); err != nil {
+		return err
+	}
+
+	_, err := s.db.Exec(`CREATE INDEX IF NOT EXISTS splits_account_version_idx ON splits (account_id, account_version)`)
+	return err
+}
+
+func (s *SQLiteStore) createSessionTable() error {
+	query := `CREATE TABLE IF NOT EXISTS sessions (
+		id TEXT PRIMARY KEY,
+		account_id INTEGER NOT NULL REFERENCES account(id),
+		refresh_token_hash TEXT NOT NULL,
+		expires_at DATETIME NOT NULL,
+		revoked_at DATETIME,
+		user_agent TEXT,
+		ip TEXT,
+		created_at DATETIME NOT NULL DEFAULT (datetime('now'))
+	)`
+	_, err := s.db.Exec(query)
+	return err
+}
+
+func (s *SQLiteStore) CreateAccount(acc *Account) error {
+	if acc.Type == "" {
+		acc.Type = AccountTypeBank
+	}
+	if acc.Role == "" {
+		acc.Role = RoleUser
+	}
+
+	query := `INSERT INTO account
+	(first_name, last_name, number, encrypted_password, balance, type, parent_id, version, created_at, role)
+	VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`
+
+	res, err := s.db.Exec(
+		query,
+		acc.FirstName,
+		acc.LastName,
+		acc.Number,
+		acc.EncryptedPassword,
+		acc.Balance,
+		acc.Type,
+		acc.ParentAccountID,
+		acc.AccountVersion,
+		acc.CreatedAt,
+		acc.Role,
+	)
+	if err != nil {
+		return err
+	}
+
+	id, err := res.LastInsertId()
+	if err != nil {
+		return err
+	}
+	acc.ID = int(id)
+	return nil
+}
+
+func (s *SQLiteStore) CreateChildAccount(parentID int, acc *Account) error {
+	if _, err := s.GetAccountByID(parentID); err != nil {
+		return fmt.Errorf("parent account %d not found: %w", parentID, err)
+	}
+
+	acc.ParentAccountID = &parentID
+	return s.CreateAccount(acc)
+}
+
+func (s *SQLiteStore) UpdateAccount(acc *Account) error {
+	query := `UPDATE account SET
+		first_name = ?,
+		last_name = ?,
+		encrypted_password = ?,
+		balance = ?
+		WHERE id = ?`
+
+	_, err := s.db.Exec(
+		query,
+		acc.FirstName,
+		acc.LastName,
+		acc.EncryptedPassword,
+		acc.Balance,
+		acc.ID,
+	)
+
+	return err
+}
+
+func (s *SQLiteStore) DeleteAccount(id int) error {
+	_, err := s.db.Exec("DELETE FROM account WHERE id = ?", id)
+	return err
+}
+
+func (s *SQLiteStore) SetAccountRole(id int, role Role) error {
+	if !role.Valid() {
+		return fmt.Errorf("invalid role %q", role)
+	}
+	_, err := s.db.Exec("UPDATE account SET role = ? WHERE id = ?", role, id)
+	return err
+}
+
+func (s *SQLiteStore) GetAccountByNumber(number int) (*Account, error) {
+	account := new(Account)
+	query := `SELECT id, first_name, last_name, number, encrypted_password, balance, type, parent_id, version, created_at, role
+		FROM account
+		WHERE number = ?`
+
+	err := s.db.QueryRow(query, number).Scan(
+		&account.ID,
+		&account.FirstName,
+		&account.LastName,
+		&account.Number,
+		&account.EncryptedPassword,
+		&account.Balance,
+		&account.Type,
+		&account.ParentAccountID,
+		&account.AccountVersion,
+		&account.CreatedAt,
+		&account.Role,
+	)
+	if err == sql.ErrNoRows {
+		return nil, fmt.Errorf("account with number [%d] not found", number)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return account, nil
+}
+
+func (s *SQLiteStore) GetAccountByID(id int) (*Account, error) {
+	account := new(Account)
+	query := `SELECT id, first_name, last_name, number, encrypted_password, balance, type, parent_id, version, created_at, role
+		FROM account
+		WHERE id = ?`
+
+	err := s.db.QueryRow(query, id).Scan(
+		&account.ID,
+		&account.FirstName,
+		&account.LastName,
+		&account.Number,
+		&account.EncryptedPassword,
+		&account.Balance,
+		&account.Type,
+		&account.ParentAccountID,
+		&account.AccountVersion,
+		&account.CreatedAt,
+		&account.Role,
+	)
+	if err == sql.ErrNoRows {
+		return nil, fmt.Errorf("account %d not found", id)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return account, nil
+}
+
+func (s *SQLiteStore) GetAccounts() ([]*Account, error) {
+	rows, err := s.db.Query(
+		"SELECT id, first_name, last_name, number, encrypted_password, balance, type, parent_id, version, created_at, role FROM account",
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	accounts := []*Account{}
+	for rows.Next() {
+		account, err := scanIntoAccount(rows)
+		if err != nil {
+			return nil, err
+		}
+		accounts = append(accounts, account)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return accounts, nil
+}
+
+func (s *SQLiteStore) GetAccountTree(rootID int) (*AccountNode, error) {
+	query := `WITH RECURSIVE subtree AS (
+		SELECT id, first_name, last_name, number, encrypted_password, balance, type, parent_id, version, created_at, role
+		FROM account WHERE id = ?
+		UNION ALL
+		SELECT a.id, a.first_name, a.last_name, a.number, a.encrypted_password, a.balance, a.type, a.parent_id, a.version, a.created_at, a.role
+		FROM account a JOIN subtree s ON a.parent_id = s.id
+	)
+	SELECT id, first_name, last_name, number, encrypted_password, balance, type, parent_id, version, created_at, role FROM subtree`
+
+	rows, err := s.db.Query(query, rootID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	nodes := map[int]*AccountNode{}
+	var order []int
+	for rows.Next() {
+		account, err := scanIntoAccount(rows)
+		if err != nil {
+			return nil, err
+		}
+		nodes[account.ID] = &AccountNode{AccountView: account.View()}
+		order = append(order, account.ID)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	root, ok := nodes[rootID]
+	if !ok {
+		return nil, fmt.Errorf("account %d not found", rootID)
+	}
+
+	for _, id := range order {
+		node := nodes[id]
+		if node.ParentAccountID == nil || id == rootID {
+			continue
+		}
+		parent, ok := nodes[*node.ParentAccountID]
+		if !ok {
+			continue
+		}
+		parent.Children = append(parent.Children, node)
+	}
+
+	return root, nil
+}
+
+func (s *SQLiteStore) ListAccountsByType(rootID int, accType AccountType) ([]*Account, error) {
+	query := `WITH RECURSIVE subtree AS (
+		SELECT id FROM account WHERE id = ?
+		UNION ALL
+		SELECT a.id FROM account a JOIN subtree s ON a.parent_id = s.id
+	)
+	SELECT a.id, a.first_name, a.last_name, a.number, a.encrypted_password, a.balance, a.type, a.parent_id, a.version, a.created_at, a.role
+	FROM account a JOIN subtree s ON a.id = s.id
+	WHERE a.type = ?`
+
+	rows, err := s.db.Query(query, rootID, accType)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	accounts := []*Account{}
+	for rows.Next() {
+		account, err := scanIntoAccount(rows)
+		if err != nil {
+			return nil, err
+		}
+		accounts = append(accounts, account)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return accounts, nil
+}
+
+// PostTransaction mirrors PostgresStore's locking strategy as closely as
+// SQLite allows: since SQLite has no per-row FOR UPDATE, and db.SetMaxOpenConns(1)
+// limits the pool to a single connection, a plain tx keeps concurrent
+// transfers from interleaving onto that connection.
+func (s *SQLiteStore) PostTransaction(t *Transaction) error {
+	if len(t.Splits) == 0 {
+		return fmt.Errorf("transaction has no splits")
+	}
+	if !t.Balanced() {
+		return fmt.Errorf("unbalanced transaction: splits must sum to zero")
+	}
+
+	tx, err := s.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	type accRow struct {
+		id      int
+		balance int64
+		accType AccountType
+	}
+
+	accountIDs := make([]int, 0, len(t.Splits))
+	seen := map[int]bool{}
+	for _, split := range t.Splits {
+		if !seen[split.AccountID] {
+			seen[split.AccountID] = true
+			accountIDs = append(accountIDs, split.AccountID)
+		}
+	}
+	sort.Ints(accountIDs)
+
+	accounts := make(map[int]*accRow, len(accountIDs))
+	for _, id := range accountIDs {
+		row := tx.QueryRow("SELECT id, balance, type FROM account WHERE id = ?", id)
+		var a accRow
+		if err := row.Scan(&a.id, &a.balance, &a.accType); err != nil {
+			if err == sql.ErrNoRows {
+				return fmt.Errorf("account %d not found", id)
+			}
+			return err
+		}
+		accounts[id] = &a
+	}
+
+	newBalances := map[int]int64{}
+	for id, a := range accounts {
+		newBalances[id] = a.balance
+	}
+	for _, split := range t.Splits {
+		newBalances[split.AccountID] += split.Amount
+	}
+	for id, balance := range newBalances {
+		accType := accounts[id].accType
+		if requiresOverdraftCheck(accType) && balance < -s.overdraftLimit {
+			return ErrInsufficientFunds
+		}
+	}
+
+	res, err := tx.Exec("INSERT INTO transactions (description, posted_at) VALUES (?, ?)", t.Description, t.PostedAt)
+	if err != nil {
+		return err
+	}
+	txID, err := res.LastInsertId()
+	if err != nil {
+		return err
+	}
+	t.ID = int(txID)
+
+	for _, split := range t.Splits {
+		id := split.AccountID
+		if _, err := tx.Exec(
+			"UPDATE account SET balance = balance + ?, version = version + 1 WHERE id = ?",
+			split.Amount,
+			id,
+		); err != nil {
+			return err
+		}
+
+		var version int64
+		if err := tx.QueryRow("SELECT version FROM account WHERE id = ?", id).Scan(&version); err != nil {
+			return err
+		}
+		split.TransactionID = t.ID
+
+		splitRes, err := tx.Exec(
+			`INSERT INTO splits (transaction_id, account_id, amount, memo, account_version)
+			VALUES (?, ?, ?, ?, ?)`,
+			split.TransactionID,
+			split.AccountID,
+			split.Amount,
+			split.Memo,
+			version,
+		)
+		if err != nil {
+			return err
+		}
+		splitID, err := splitRes.LastInsertId()
+		if err != nil {
+			return err
+		}
+		split.ID = int(splitID)
+	}
+
+	return tx.Commit()
+}
+
+func (s *SQLiteStore) GetLedger(accountID int, since int64) ([]*Split, error) {
+	rows, err := s.db.Query(
+		`SELECT id, transaction_id, account_id, amount, memo, account_version
+		FROM splits
+		WHERE account_id = ? AND account_version > ?
+		ORDER BY account_version ASC`,
+		accountID,
+		since,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	splits := []*Split{}
+	for rows.Next() {
+		split := new(Split)
+		var version int64
+		if err := rows.Scan(&split.ID, &split.TransactionID, &split.AccountID, &split.Amount, &split.Memo, &version); err != nil {
+			return nil, err
+		}
+		splits = append(splits, split)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return splits, nil
+}
+
+func (s *SQLiteStore) CreateSession(session *Session) error {
+	query := `INSERT INTO sessions
+	(id, account_id, refresh_token_hash, expires_at, user_agent, ip)
+	VALUES (?, ?, ?, ?, ?, ?)`
+
+	_, err := s.db.Exec(
+		query,
+		session.ID,
+		session.AccountID,
+		session.RefreshTokenHash,
+		session.ExpiresAt,
+		session.UserAgent,
+		session.IP,
+	)
+	return err
+}
+
+func (s *SQLiteStore) GetSessionByID(id string) (*Session, error) {
+	session := new(Session)
+	query := `SELECT id, account_id, refresh_token_hash, expires_at, revoked_at, user_agent, ip
+		FROM sessions
+		WHERE id = ?`
+
+	err := s.db.QueryRow(query, id).Scan(
+		&session.ID,
+		&session.AccountID,
+		&session.RefreshTokenHash,
+		&session.ExpiresAt,
+		&session.RevokedAt,
+		&session.UserAgent,
+		&session.IP,
+	)
+	if err == sql.ErrNoRows {
+		return nil, fmt.Errorf("session %s not found", id)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return session, nil
+}
+
+func (s *SQLiteStore) RevokeSession(id string) error {
+	_, err := s.db.Exec(`UPDATE sessions SET revoked_at = ? WHERE id = ? AND revoked_at IS NULL`, time.Now().UTC(), id)
+	return err
+}
+
+func (s *SQLiteStore) RevokeAllSessions(accountID int) error {
+	_, err := s.db.Exec(`UPDATE sessions SET revoked_at = ? WHERE account_id = ? AND revoked_at IS NULL`, time.Now().UTC(), accountID)
+	return err
+}
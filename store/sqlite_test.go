@@ -0,0 +1,22 @@
+package store_test
+
+import (
+	"os"
+	"testing"
+
+	"github.com/AbhiAnand-1011/siuu-bank/store"
+	"github.com/AbhiAnand-1011/siuu-bank/store/storagetest"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSQLiteStore(t *testing.T) {
+	path := t.TempDir() + "/storagetest.db"
+	t.Setenv("SQLITE_PATH", path)
+	defer os.Remove(path)
+
+	s, err := store.NewSQLiteStore()
+	require.NoError(t, err)
+	require.NoError(t, s.Init())
+
+	storagetest.Run(t, s)
+}
@@ -0,0 +1,280 @@
+// Package storagetest exercises the store.Storage contract against any
+// backend. Each concrete backend's own test package (e.g. store/sqlite_test.go)
+// constructs a fresh, initialized store and hands it to Run so the same
+// behavioral assertions apply no matter which driver is under test.
+package storagetest
+
+import (
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/AbhiAnand-1011/siuu-bank/store"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// Run exercises store.Storage's account, ledger, and session behavior
+// against s, which must already be Init'd and empty.
+func Run(t *testing.T, s store.Storage) {
+	t.Run("CreateAndFetchAccount", func(t *testing.T) { testCreateAndFetchAccount(t, s) })
+	t.Run("ChartOfAccountsTree", func(t *testing.T) { testChartOfAccountsTree(t, s) })
+	t.Run("GetAccounts", func(t *testing.T) { testGetAccounts(t, s) })
+	t.Run("DeleteAccount", func(t *testing.T) { testDeleteAccount(t, s) })
+	t.Run("SetAccountRole", func(t *testing.T) { testSetAccountRole(t, s) })
+	t.Run("PostTransaction", func(t *testing.T) { testPostTransaction(t, s) })
+	t.Run("PostTransactionInsufficientFunds", func(t *testing.T) { testPostTransactionInsufficientFunds(t, s) })
+	t.Run("ConcurrentTransfer", func(t *testing.T) { testConcurrentTransfer(t, s) })
+	t.Run("Sessions", func(t *testing.T) { testSessions(t, s) })
+	t.Run("RevokeAllSessions", func(t *testing.T) { testRevokeAllSessions(t, s) })
+}
+
+func testCreateAndFetchAccount(t *testing.T, s store.Storage) {
+	acc, err := store.NewAccount("Ada", "Lovelace", "password123")
+	require.NoError(t, err)
+	require.NoError(t, s.CreateAccount(acc))
+	require.NotZero(t, acc.ID)
+
+	fetched, err := s.GetAccountByID(acc.ID)
+	require.NoError(t, err)
+	assert.Equal(t, acc.Number, fetched.Number)
+	assert.Equal(t, store.AccountTypeBank, fetched.Type)
+
+	byNumber, err := s.GetAccountByNumber(int(acc.Number))
+	require.NoError(t, err)
+	assert.Equal(t, acc.ID, byNumber.ID)
+}
+
+func testChartOfAccountsTree(t *testing.T, s store.Storage) {
+	root, err := store.NewAccount("Root", "Owner", "password123")
+	require.NoError(t, err)
+	require.NoError(t, s.CreateAccount(root))
+
+	checking, err := store.NewChildAccount(root.ID, store.AccountTypeBank, "Root", "Checking")
+	require.NoError(t, err)
+	require.NoError(t, s.CreateChildAccount(root.ID, checking))
+
+	groceries, err := store.NewChildAccount(root.ID, store.AccountTypeExpense, "Root", "Groceries")
+	require.NoError(t, err)
+	require.NoError(t, s.CreateChildAccount(root.ID, groceries))
+
+	tree, err := s.GetAccountTree(root.ID)
+	require.NoError(t, err)
+	assert.Equal(t, root.ID, tree.ID)
+	assert.Len(t, tree.Children, 2)
+
+	expenses, err := s.ListAccountsByType(root.ID, store.AccountTypeExpense)
+	require.NoError(t, err)
+	require.Len(t, expenses, 1)
+	assert.Equal(t, groceries.ID, expenses[0].ID)
+}
+
+func testGetAccounts(t *testing.T, s store.Storage) {
+	before, err := s.GetAccounts()
+	require.NoError(t, err)
+
+	acc, err := store.NewAccount("List", "Me", "password123")
+	require.NoError(t, err)
+	require.NoError(t, s.CreateAccount(acc))
+
+	after, err := s.GetAccounts()
+	require.NoError(t, err)
+	assert.Len(t, after, len(before)+1)
+}
+
+func testDeleteAccount(t *testing.T, s store.Storage) {
+	acc, err := store.NewAccount("Delete", "Me", "password123")
+	require.NoError(t, err)
+	require.NoError(t, s.CreateAccount(acc))
+
+	require.NoError(t, s.DeleteAccount(acc.ID))
+
+	_, err = s.GetAccountByID(acc.ID)
+	assert.Error(t, err)
+}
+
+func testSetAccountRole(t *testing.T, s store.Storage) {
+	acc, err := store.NewAccount("Promote", "Me", "password123")
+	require.NoError(t, err)
+	require.NoError(t, s.CreateAccount(acc))
+	assert.Equal(t, store.RoleUser, acc.Role)
+
+	require.NoError(t, s.SetAccountRole(acc.ID, store.RoleAdmin))
+
+	fetched, err := s.GetAccountByID(acc.ID)
+	require.NoError(t, err)
+	assert.Equal(t, store.RoleAdmin, fetched.Role)
+}
+
+func testPostTransaction(t *testing.T, s store.Storage) {
+	from, err := store.NewAccount("From", "Account", "password123")
+	require.NoError(t, err)
+	require.NoError(t, s.CreateAccount(from))
+
+	to, err := store.NewAccount("To", "Account", "password123")
+	require.NoError(t, err)
+	require.NoError(t, s.CreateAccount(to))
+
+	from.Balance = 10_000
+	require.NoError(t, s.UpdateAccount(from))
+
+	transfer, err := store.NewTransfer(from.ID, to.ID, 2_500, "test transfer")
+	require.NoError(t, err)
+	require.NoError(t, s.PostTransaction(transfer))
+
+	fromAfter, err := s.GetAccountByID(from.ID)
+	require.NoError(t, err)
+	assert.Equal(t, int64(7_500), fromAfter.Balance)
+
+	toAfter, err := s.GetAccountByID(to.ID)
+	require.NoError(t, err)
+	assert.Equal(t, int64(2_500), toAfter.Balance)
+
+	ledger, err := s.GetLedger(from.ID, 0)
+	require.NoError(t, err)
+	require.Len(t, ledger, 1)
+	assert.Equal(t, int64(-2_500), ledger[0].Amount)
+}
+
+func testPostTransactionInsufficientFunds(t *testing.T, s store.Storage) {
+	from, err := store.NewAccount("Poor", "Account", "password123")
+	require.NoError(t, err)
+	require.NoError(t, s.CreateAccount(from))
+
+	to, err := store.NewAccount("Rich", "Account", "password123")
+	require.NoError(t, err)
+	require.NoError(t, s.CreateAccount(to))
+
+	transfer, err := store.NewTransfer(from.ID, to.ID, 1_000, "overdraft attempt")
+	require.NoError(t, err)
+	err = s.PostTransaction(transfer)
+	assert.ErrorIs(t, err, store.ErrInsufficientFunds)
+}
+
+// testConcurrentTransfer fires many PostTransaction calls at the same pair
+// of accounts in parallel. A correct backend serializes them (each either
+// commits cleanly or, once the payer is overdrawn, fails with
+// ErrInsufficientFunds) and leaves the ledger's sum of splits consistent
+// with the final balances; it must never surface a driver-level error like
+// a nested-transaction or locking failure.
+func testConcurrentTransfer(t *testing.T, s store.Storage) {
+	from, err := store.NewAccount("Concurrent", "From", "password123")
+	require.NoError(t, err)
+	require.NoError(t, s.CreateAccount(from))
+
+	to, err := store.NewAccount("Concurrent", "To", "password123")
+	require.NoError(t, err)
+	require.NoError(t, s.CreateAccount(to))
+
+	from.Balance = 10_000
+	require.NoError(t, s.UpdateAccount(from))
+
+	const transfers = 50
+	const amount = 100
+
+	var wg sync.WaitGroup
+	errs := make([]error, transfers)
+	for i := 0; i < transfers; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			transfer, err := store.NewTransfer(from.ID, to.ID, amount, "concurrent transfer")
+			if err != nil {
+				errs[i] = err
+				return
+			}
+			errs[i] = s.PostTransaction(transfer)
+		}(i)
+	}
+	wg.Wait()
+
+	succeeded := 0
+	for _, err := range errs {
+		if err == nil {
+			succeeded++
+			continue
+		}
+		require.ErrorIs(t, err, store.ErrInsufficientFunds)
+	}
+	require.Equal(t, transfers, succeeded, "every transfer had sufficient funds and should have succeeded")
+
+	fromAfter, err := s.GetAccountByID(from.ID)
+	require.NoError(t, err)
+	assert.Equal(t, from.Balance-int64(succeeded*amount), fromAfter.Balance)
+
+	toAfter, err := s.GetAccountByID(to.ID)
+	require.NoError(t, err)
+	assert.Equal(t, int64(succeeded*amount), toAfter.Balance)
+
+	ledger, err := s.GetLedger(from.ID, 0)
+	require.NoError(t, err)
+	assert.Len(t, ledger, succeeded)
+}
+
+func testSessions(t *testing.T, s store.Storage) {
+	acc, err := store.NewAccount("Session", "Owner", "password123")
+	require.NoError(t, err)
+	require.NoError(t, s.CreateAccount(acc))
+
+	sid, err := store.NewSessionID()
+	require.NoError(t, err)
+
+	token, err := store.NewRefreshToken(sid)
+	require.NoError(t, err)
+
+	hash, err := store.HashRefreshToken(token)
+	require.NoError(t, err)
+
+	session := &store.Session{
+		ID:               sid,
+		AccountID:        acc.ID,
+		RefreshTokenHash: hash,
+		ExpiresAt:        time.Now().UTC().Add(store.RefreshTokenTTL),
+	}
+	require.NoError(t, s.CreateSession(session))
+
+	fetched, err := s.GetSessionByID(sid)
+	require.NoError(t, err)
+	assert.True(t, fetched.Valid())
+	assert.True(t, fetched.ValidRefreshToken(token))
+
+	require.NoError(t, s.RevokeSession(sid))
+	revoked, err := s.GetSessionByID(sid)
+	require.NoError(t, err)
+	assert.False(t, revoked.Valid())
+}
+
+func testRevokeAllSessions(t *testing.T, s store.Storage) {
+	acc, err := store.NewAccount("MultiSession", "Owner", "password123")
+	require.NoError(t, err)
+	require.NoError(t, s.CreateAccount(acc))
+
+	var sessions []*store.Session
+	for i := 0; i < 2; i++ {
+		sid, err := store.NewSessionID()
+		require.NoError(t, err)
+
+		token, err := store.NewRefreshToken(sid)
+		require.NoError(t, err)
+
+		hash, err := store.HashRefreshToken(token)
+		require.NoError(t, err)
+
+		session := &store.Session{
+			ID:               sid,
+			AccountID:        acc.ID,
+			RefreshTokenHash: hash,
+			ExpiresAt:        time.Now().UTC().Add(store.RefreshTokenTTL),
+		}
+		require.NoError(t, s.CreateSession(session))
+		sessions = append(sessions, session)
+	}
+
+	require.NoError(t, s.RevokeAllSessions(acc.ID))
+
+	for _, session := range sessions {
+		fetched, err := s.GetSessionByID(session.ID)
+		require.NoError(t, err)
+		assert.False(t, fetched.Valid())
+	}
+}
@@ -0,0 +1,87 @@
+package store
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"os"
+)
+
+var ErrInsufficientFunds = errors.New("insufficient funds")
+var ErrIncompatibleAccountTypes = errors.New("incompatible account types for a direct transfer")
+
+// SessionStore persists login sessions so access tokens can be revoked
+// server-side instead of simply expiring.
+type SessionStore interface {
+	CreateSession(*Session) error
+	GetSessionByID(id string) (*Session, error)
+	RevokeSession(id string) error
+	RevokeAllSessions(accountID int) error
+}
+
+//go:generate mockgen -package mockstore -destination mock/store.go . Storage
+type Storage interface {
+	CreateAccount(*Account) error
+	DeleteAccount(int) error
+	UpdateAccount(*Account) error
+	SetAccountRole(id int, role Role) error
+	GetAccounts() ([]*Account, error)
+	GetAccountByID(int) (*Account, error)
+	GetAccountByNumber(int) (*Account, error)
+
+	CreateChildAccount(parentID int, acc *Account) error
+	GetAccountTree(rootID int) (*AccountNode, error)
+	ListAccountsByType(rootID int, accType AccountType) ([]*Account, error)
+
+	PostTransaction(*Transaction) error
+	GetLedger(accountID int, since int64) ([]*Split, error)
+
+	SessionStore
+
+	Init() error
+}
+
+// requiresOverdraftCheck reports whether accType's balance must not be
+// driven below -overdraftLimit by a transaction. Shared by every backend's
+// PostTransaction.
+func requiresOverdraftCheck(accType AccountType) bool {
+	switch accType {
+	case AccountTypeBank, AccountTypeCash, AccountTypeAsset, AccountTypeLiability:
+		return true
+	default:
+		return false
+	}
+}
+
+func scanIntoAccount(rows *sql.Rows) (*Account, error) {
+	account := new(Account)
+	err := rows.Scan(
+		&account.ID,
+		&account.FirstName,
+		&account.LastName,
+		&account.Number,
+		&account.EncryptedPassword,
+		&account.Balance,
+		&account.Type,
+		&account.ParentAccountID,
+		&account.AccountVersion,
+		&account.CreatedAt,
+		&account.Role,
+	)
+
+	return account, err
+}
+
+// New chooses the concrete Storage backend from the STORAGE_BACKEND
+// environment variable ("postgres" or "sqlite"), defaulting to postgres.
+// This is the only place main.go needs to touch to swap backends.
+func New() (Storage, error) {
+	switch os.Getenv("STORAGE_BACKEND") {
+	case "sqlite":
+		return NewSQLiteStore()
+	case "", "postgres":
+		return NewPostgresStore()
+	default:
+		return nil, fmt.Errorf("unknown STORAGE_BACKEND %q", os.Getenv("STORAGE_BACKEND"))
+	}
+}
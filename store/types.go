@@ -0,0 +1,175 @@
+package store
+
+import (
+	"fmt"
+	"math/rand"
+	"time"
+
+	"golang.org/x/crypto/bcrypt"
+)
+
+var bcryptCost = bcrypt.DefaultCost
+
+// AccountType classifies an account within the chart of accounts. It
+// determines which transfers are legal bank-to-bank movements versus
+// which require a journal entry (see handleTransfer in the API layer).
+type AccountType string
+
+const (
+	AccountTypeBank       AccountType = "bank"
+	AccountTypeCash       AccountType = "cash"
+	AccountTypeAsset      AccountType = "asset"
+	AccountTypeLiability  AccountType = "liability"
+	AccountTypeInvestment AccountType = "investment"
+	AccountTypeIncome     AccountType = "income"
+	AccountTypeExpense    AccountType = "expense"
+	AccountTypeTrading    AccountType = "trading"
+	AccountTypeEquity     AccountType = "equity"
+	AccountTypeReceivable AccountType = "receivable"
+	AccountTypePayable    AccountType = "payable"
+)
+
+func (t AccountType) Valid() bool {
+	switch t {
+	case AccountTypeBank, AccountTypeCash, AccountTypeAsset, AccountTypeLiability,
+		AccountTypeInvestment, AccountTypeIncome, AccountTypeExpense, AccountTypeTrading,
+		AccountTypeEquity, AccountTypeReceivable, AccountTypePayable:
+		return true
+	default:
+		return false
+	}
+}
+
+// LedgerType reports whether transfers directly touching this account
+// type move real money between bank-like accounts (true) or represent
+// the other side of a journal entry, such as income and expense (false).
+func (t AccountType) LedgerType() bool {
+	switch t {
+	case AccountTypeIncome, AccountTypeExpense, AccountTypeEquity:
+		return false
+	default:
+		return true
+	}
+}
+
+// Role is an account's authorization level. It is independent of
+// AccountType: a bank account and a sub-ledger account can both be held by
+// an admin user.
+type Role string
+
+const (
+	RoleUser  Role = "user"
+	RoleAdmin Role = "admin"
+)
+
+func (r Role) Valid() bool {
+	switch r {
+	case RoleUser, RoleAdmin:
+		return true
+	default:
+		return false
+	}
+}
+
+type Account struct {
+	ID                int         `json:"id"`
+	FirstName         string      `json:"firstName"`
+	LastName          string      `json:"lastName"`
+	Number            int64       `json:"number"`
+	EncryptedPassword string      `json:"-"`
+	Balance           int64       `json:"balance"`
+	Type              AccountType `json:"type"`
+	Role              Role        `json:"role"`
+	ParentAccountID   *int        `json:"parentAccountId,omitempty"`
+	AccountVersion    int64       `json:"accountVersion"`
+	CreatedAt         time.Time   `json:"createdAt"`
+}
+
+type AccountView struct {
+	ID              int         `json:"id"`
+	FirstName       string      `json:"firstName"`
+	LastName        string      `json:"lastName"`
+	Number          int64       `json:"number"`
+	Balance         int64       `json:"balance"`
+	Type            AccountType `json:"type"`
+	Role            Role        `json:"role"`
+	ParentAccountID *int        `json:"parentAccountId,omitempty"`
+	AccountVersion  int64       `json:"accountVersion"`
+	CreatedAt       time.Time   `json:"createdAt"`
+}
+
+func (a *Account) View() AccountView {
+	return AccountView{
+		ID:              a.ID,
+		FirstName:       a.FirstName,
+		LastName:        a.LastName,
+		Number:          a.Number,
+		Balance:         a.Balance,
+		Type:            a.Type,
+		Role:            a.Role,
+		ParentAccountID: a.ParentAccountID,
+		AccountVersion:  a.AccountVersion,
+		CreatedAt:       a.CreatedAt,
+	}
+}
+
+// AccountNode is an Account together with its direct children, used to
+// render a chart of accounts as a nested JSON tree.
+type AccountNode struct {
+	AccountView
+	Children []*AccountNode `json:"children,omitempty"`
+}
+
+func (a *Account) FullName() string {
+	return a.FirstName + " " + a.LastName
+}
+
+func (a *Account) ValidPassword(pw string) bool {
+	return bcrypt.CompareHashAndPassword(
+		[]byte(a.EncryptedPassword),
+		[]byte(pw),
+	) == nil
+}
+
+func generateAccountNumber() int64 {
+	return rand.Int63n(1_000_000_000_000)
+}
+
+func NewAccount(firstName, lastName, password string) (*Account, error) {
+	encpw, err := bcrypt.GenerateFromPassword(
+		[]byte(password),
+		bcryptCost,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Account{
+		FirstName:         firstName,
+		LastName:          lastName,
+		EncryptedPassword: string(encpw),
+		Number:            generateAccountNumber(),
+		Type:              AccountTypeBank,
+		Role:              RoleUser,
+		CreatedAt:         time.Now().UTC(),
+	}, nil
+}
+
+// NewChildAccount builds a sub-account of the given type underneath
+// parentID. It shares the parent's chart-of-accounts tree but has no
+// login credentials of its own.
+func NewChildAccount(parentID int, accType AccountType, firstName, lastName string) (*Account, error) {
+	if !accType.Valid() {
+		return nil, fmt.Errorf("invalid account type %q", accType)
+	}
+
+	return &Account{
+		FirstName:       firstName,
+		LastName:        lastName,
+		Number:          generateAccountNumber(),
+		Type:            accType,
+		Role:            RoleUser,
+		ParentAccountID: &parentID,
+		CreatedAt:       time.Now().UTC(),
+	}, nil
+}